@@ -0,0 +1,122 @@
+package icbc_api_sdk_go
+
+import (
+	"context"
+	"fmt"
+)
+
+// QueryOrder 查询订单，接口地址由 BaseURL 或 Environment 与 PathOrderQuery 拼接
+//
+// 参数:
+//   - ctx: 上下文
+//   - query: 订单查询请求
+//
+// 返回值:
+//   - *OrderQueryResp: 查询结果，业务是否成功需检查 ReturnCode
+//   - *CallResult: 调用信息，签名完成后即使调用失败也会返回
+//   - error: 错误信息
+func (c *DefaultClient) QueryOrder(ctx context.Context, query *OrderQueryRequest) (*OrderQueryResp, *CallResult, error) {
+	if query == nil {
+		return nil, nil, fmt.Errorf("query cannot be nil")
+	}
+	resp := &OrderQueryResp{}
+	result, err := c.executeAPI(ctx, PathOrderQuery, query, true, resp)
+	if err != nil {
+		return nil, result, err
+	}
+	return resp, result, nil
+}
+
+// Refund 发起退款，接口地址由 BaseURL 或 Environment 与 PathRefund 拼接
+//
+// 退款请求不会自动重试，结果不确定时应使用 RefundAndConfirm 或 QueryRefund 确认，也可凭 CallResult 中的 msg_id 向工行核实。
+//
+// 参数:
+//   - ctx: 上下文
+//   - req: 退款请求
+//
+// 返回值:
+//   - *RefundResp: 退款结果，业务是否成功需检查 ReturnCode
+//   - *CallResult: 调用信息，签名完成后即使调用失败也会返回
+//   - error: 错误信息
+func (c *DefaultClient) Refund(ctx context.Context, req *RefundRequest) (*RefundResp, *CallResult, error) {
+	if req == nil {
+		return nil, nil, fmt.Errorf("refund request cannot be nil")
+	}
+	resp := &RefundResp{}
+	result, err := c.executeAPI(ctx, PathRefund, req, false, resp)
+	if err != nil {
+		return nil, result, err
+	}
+	return resp, result, nil
+}
+
+// QueryRefund 查询退款，接口地址由 BaseURL 或 Environment 与 PathRefundQuery 拼接
+//
+// 参数:
+//   - ctx: 上下文
+//   - query: 退款查询请求
+//
+// 返回值:
+//   - *QueryRefundResp: 查询结果，业务是否成功需检查 ReturnCode
+//   - *CallResult: 调用信息，签名完成后即使调用失败也会返回
+//   - error: 错误信息
+func (c *DefaultClient) QueryRefund(ctx context.Context, query *QueryRefundRequest) (*QueryRefundResp, *CallResult, error) {
+	if query == nil {
+		return nil, nil, fmt.Errorf("query cannot be nil")
+	}
+	resp := &QueryRefundResp{}
+	result, err := c.executeAPI(ctx, PathRefundQuery, query, true, resp)
+	if err != nil {
+		return nil, result, err
+	}
+	return resp, result, nil
+}
+
+// BuildPayForm 构建线上POS聚合支付下单页面的POST表单，接口地址由 BaseURL 或 Environment 与 PathShowPayUI 拼接
+//
+// 参数:
+//   - req: 下单请求
+//
+// 返回值:
+//   - string: 构建好的POST表单
+//   - error: 错误信息
+func (c *UiIcbcClient) BuildPayForm(req *ShowPayUIRequest) (string, error) {
+	if req == nil {
+		return "", fmt.Errorf("pay request cannot be nil")
+	}
+	serviceUrl, err := c.ServiceURL(PathShowPayUI)
+	if err != nil {
+		return "", err
+	}
+	return c.BuildPostForm(&ICBCRequest{
+		ServiceUrl: serviceUrl,
+		BizContent: req,
+		Method:     "POST",
+	})
+}
+
+// executeAPI 拼接接口地址并执行请求
+//
+// 参数:
+//   - ctx: 上下文
+//   - path: 接口路径
+//   - biz: 业务参数
+//   - idempotent: 是否幂等，幂等请求按 RetryPolicy 重试
+//   - res: 响应结果
+//
+// 返回值:
+//   - *CallResult: 调用信息，签名前失败时为 nil
+//   - error: 错误信息
+func (c *DefaultClient) executeAPI(ctx context.Context, path string, biz any, idempotent bool, res any) (*CallResult, error) {
+	serviceUrl, err := c.ServiceURL(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.ExecuteCall(ctx, &ICBCRequest{
+		ServiceUrl: serviceUrl,
+		BizContent: biz,
+		Method:     "POST",
+		Idempotent: idempotent,
+	}, "", res)
+}
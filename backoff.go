@@ -0,0 +1,84 @@
+package icbc_api_sdk_go
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff 指数退避配置
+type Backoff struct {
+	InitialInterval time.Duration // 首次等待时间
+	MaxInterval     time.Duration // 最大等待时间
+	Multiplier      float64       // 每次等待时间的增长倍数
+	Jitter          float64       // 随机抖动比例，取值 0~1，如 0.2 表示在 ±20% 范围内随机
+}
+
+// DefaultBackoff 默认退避配置：1 秒起步，每次翻倍，最长 30 秒，±20% 抖动
+var DefaultBackoff = Backoff{
+	InitialInterval: time.Second,
+	MaxInterval:     30 * time.Second,
+	Multiplier:      2,
+	Jitter:          0.2,
+}
+
+// Delay 计算第 attempt 次(从 0 开始)重试前需要等待的时间
+//
+// 参数:
+//   - attempt: 重试次数，从 0 开始
+//
+// 返回值:
+//   - time.Duration: 等待时间
+func (b Backoff) Delay(attempt int) time.Duration {
+	interval := float64(b.InitialInterval)
+	if interval <= 0 {
+		interval = float64(DefaultBackoff.InitialInterval)
+	}
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	maxInterval := float64(b.MaxInterval)
+	for i := 0; i < attempt; i++ {
+		interval *= multiplier
+		if maxInterval > 0 && interval >= maxInterval {
+			interval = maxInterval
+			break
+		}
+	}
+	if maxInterval > 0 && interval > maxInterval {
+		interval = maxInterval
+	}
+
+	// 在 [1-jitter, 1+jitter] 范围内随机缩放
+	if b.Jitter > 0 {
+		jitter := b.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		interval *= 1 - jitter + 2*jitter*rand.Float64()
+	}
+	return time.Duration(interval)
+}
+
+// sleepContext 等待指定时间，context 结束时提前返回
+//
+// 参数:
+//   - ctx: 上下文
+//   - d: 等待时间
+//
+// 返回值:
+//   - error: context 结束时返回 ctx.Err()
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -2,11 +2,15 @@ package icbc_api_sdk_go
 
 import "encoding/json"
 
+// ReturnCodeSuccess 工行接口返回码：成功
+const ReturnCodeSuccess = "0"
+
 type ICBCRequest struct {
 	ServiceUrl  string
 	BizContent  interface{}
-	ExtraParams map[string]string
-	Method      string
+	ExtraParams map[string]string // 额外的公共参数，与 app_id 等一起签名，不能与 ApiParamNames 或 biz_content 重名
+	Method      string            // 请求方法，GET 时参数放在查询字符串中，POST 或为空时参数放在表单请求体中
+	Idempotent  bool              // 是否为幂等接口(如查询类接口)，幂等接口才会按 RetryPolicy 重试
 }
 
 type IcbcResponse struct {
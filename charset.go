@@ -0,0 +1,99 @@
+package icbc_api_sdk_go
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 支持的字符集
+const (
+	CharsetUTF8 = "UTF-8"
+	CharsetGBK  = "GBK"
+)
+
+// charset 返回客户端使用的字符集，未配置时为 UTF-8
+func (c *DefaultClient) charset() string {
+	if c.Charset == "" {
+		return CharsetUTF8
+	}
+	return strings.ToUpper(c.Charset)
+}
+
+// isGBK 判断字符集是否为 GBK
+func isGBK(charset string) bool {
+	return strings.EqualFold(charset, CharsetGBK)
+}
+
+// validateCharset 校验字符集是否受支持
+func validateCharset(charset string) error {
+	if charset == "" || strings.EqualFold(charset, CharsetUTF8) || isGBK(charset) {
+		return nil
+	}
+	return fmt.Errorf("unsupported charset %q, expected %s or %s", charset, CharsetUTF8, CharsetGBK)
+}
+
+// encodeCharset 将 UTF-8 字符串编码为指定字符集的字节，以字符串形式返回
+//
+// 参数:
+//   - charset: 目标字符集
+//   - s: UTF-8 字符串
+//
+// 返回值:
+//   - string: 编码后的字节
+//   - error: 存在无法编码的字符时返回错误
+func encodeCharset(charset, s string) (string, error) {
+	if !isGBK(charset) {
+		return s, nil
+	}
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", CharsetGBK, err)
+	}
+	return encoded, nil
+}
+
+// decodeCharset 将指定字符集的字节解码为 UTF-8
+//
+// 参数:
+//   - charset: 源字符集
+//   - b: 原始字节
+//
+// 返回值:
+//   - []byte: UTF-8 字节
+//   - error: 错误信息
+func decodeCharset(charset string, b []byte) ([]byte, error) {
+	if !isGBK(charset) {
+		return b, nil
+	}
+	decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", CharsetGBK, err)
+	}
+	return decoded, nil
+}
+
+// encodeParams 将参数值编码为指定字符集，用于发送请求
+//
+// 参数:
+//   - charset: 目标字符集
+//   - params: UTF-8 参数
+//
+// 返回值:
+//   - *IcbcMap: 编码后的参数，UTF-8 时原样返回
+//   - error: 错误信息
+func encodeParams(charset string, params *IcbcMap) (*IcbcMap, error) {
+	if !isGBK(charset) {
+		return params, nil
+	}
+	encoded := NewIcbcMap()
+	for k, v := range params.All() {
+		ev, err := encodeCharset(charset, v)
+		if err != nil {
+			return nil, fmt.Errorf("param %s: %w", k, err)
+		}
+		encoded.PutString(k, ev)
+	}
+	return encoded, nil
+}
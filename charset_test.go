@@ -0,0 +1,65 @@
+package icbc_api_sdk_go_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestVerifyResponseGBKRawBytes(t *testing.T) {
+	gatewayKey, gatewayPub, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	encode := func(s string) string {
+		b, err := simplifiedchinese.GBK.NewEncoder().String(s)
+		if err != nil {
+			t.Fatalf("failed to encode %q: %v", s, err)
+		}
+		return b
+	}
+	// 乗、亇 的 GBK 第二个字节分别为 '\' 和 '}'；"\x81 " 不是合法的 GBK 序列，解码后无法再编码回原始字节
+	biz := `{"return_code":"0","return_msg":"` + encode("乗亇") + "\x81 " + `"}`
+	sign, err := icbc.SignWithSHA1RSA(biz, gatewayKey)
+	if err != nil {
+		t.Fatalf("SignWithSHA1RSA: %v", err)
+	}
+	body := `{"response_biz_content":` + biz + `,"sign":"` + sign + `"}`
+	wantMsg := "乗亇� "
+
+	client := &icbc.DefaultClient{IcbcPublicKey: gatewayPub, Charset: icbc.CharsetGBK}
+	bizContent, err := client.VerifyResponse([]byte(body))
+	if err != nil {
+		t.Fatalf("VerifyResponse: %v", err)
+	}
+	if want := `{"return_code":"0","return_msg":"` + wantMsg + `"}`; string(bizContent) != want {
+		t.Errorf("VerifyResponse = %s, want %s", bizContent, want)
+	}
+
+	merchantKey, _, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	client.PrivateKey = merchantKey
+	client.SignType = icbc.SignTypeRSA2
+	var resp struct {
+		ResponseBizContent struct {
+			ReturnMsg string `json:"return_msg"`
+		} `json:"response_biz_content"`
+	}
+	if _, err := client.ExecuteWithContext(context.Background(), &icbc.ICBCRequest{ServiceUrl: srv.URL + icbc.PathOrderQuery}, "", &resp); err != nil {
+		t.Fatalf("ExecuteWithContext: %v", err)
+	}
+	if resp.ResponseBizContent.ReturnMsg != wantMsg {
+		t.Errorf("return_msg = %q, want %q", resp.ResponseBizContent.ReturnMsg, wantMsg)
+	}
+}
@@ -1,34 +1,64 @@
 package icbc_api_sdk_go
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	URL "net/url"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/exp/slices"
 )
 
 const version = "v2_20190522"
 
+// defaultHTTPClient 未配置 HTTPClient 时所有客户端共享的默认HTTP客户端，复用连接池
+var defaultHTTPClient = &http.Client{
+	Timeout: time.Second * 30, // 延长超时时间
+	Transport: &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
 // DefaultClient 默认客户端
 type DefaultClient struct {
-	APPID         string
-	PrivateKey    string
-	SignType      string
-	IcbcPublicKey string
-	HTTPClient    *http.Client // 允许自定义HTTP客户端
+	APPID          string
+	PrivateKey     string
+	SignType       string
+	IcbcPublicKey  string
+	IcbcPublicKeys []string       // 额外接受的工行网关公钥，网关轮换公钥期间验签依次尝试 IcbcPublicKey 和这些公钥
+	Keys           *KeyRing       // 可在运行时轮换的密钥，配置后替代 PrivateKey、IcbcPublicKey 和 IcbcPublicKeys
+	BaseURL        string         // 网关地址，优先于 Environment，如本地模拟网关地址
+	Environment    Environment    // 网关环境，BaseURL 为空时使用对应的网关地址
+	HTTPClient     *http.Client   // 允许自定义HTTP客户端
+	RetryPolicy    *RetryPolicy   // 重试策略，为 nil 时不重试
+	Interceptors   []Interceptor  // 拦截器链，按顺序包裹每次接口调用
+	Logger         *slog.Logger   // 日志，为 nil 时不输出任何日志
+	MsgIDGenerator MsgIDGenerator // msg_id 生成器，未指定 msg_id 时使用，为 nil 时生成 UUID V7
+	Clock          Clock          // 生成 timestamp 参数的时钟，为 nil 时使用系统时间
+	Charset        string         // 请求、响应和异步通知的字符集，UTF-8(默认) 或 GBK
+	// MaxResponseSize 响应体的最大字节数，超过时返回 ErrResponseTooLarge，0 时使用 DefaultMaxResponseSize
+	MaxResponseSize int64
 }
 
 // UiIcbcClient 页面类客户端
 type UiIcbcClient struct {
 	DefaultClient
+	FormOptions *FormOptions // 默认的表单渲染选项，如自定义模板和文案；BuildPostFormWithOptions 传入 nil 时使用
+	// MaxRedirectURLLength BuildRedirectURL 生成的URL最大长度，0 时使用 DefaultMaxRedirectURLLength
+	MaxRedirectURLLength int
 }
 
+// DefaultMaxRedirectURLLength 跳转URL的默认最大长度，部分浏览器和移动端 WebView 无法处理更长的URL
+const DefaultMaxRedirectURLLength = 2048
+
 // BuildPostForm UI类客户端 构建POST表单页面
 //
 // 参数:
@@ -38,6 +68,19 @@ type UiIcbcClient struct {
 //   - string: 构建好的POST表单
 //   - error: 错误信息
 func (c *UiIcbcClient) BuildPostForm(request *ICBCRequest) (string, error) {
+	return c.BuildPostFormWithOptions(request, nil)
+}
+
+// BuildPostFormWithOptions UI类客户端 按渲染选项构建POST表单页面，如设置CSP nonce、自定义模板或不输出脚本
+//
+// 参数:
+//   - request: 请求对象
+//   - opts: 渲染选项，为 nil 时使用 FormOptions
+//
+// 返回值:
+//   - string: 构建好的POST表单
+//   - error: 错误信息
+func (c *UiIcbcClient) BuildPostFormWithOptions(request *ICBCRequest, opts *FormOptions) (string, error) {
 	// 验证请求对象
 	if request == nil {
 		return "", fmt.Errorf("request cannot be nil")
@@ -52,7 +95,10 @@ func (c *UiIcbcClient) BuildPostForm(request *ICBCRequest) (string, error) {
 		return "", fmt.Errorf("failed to prepare params: %w", err)
 	}
 
-	queryParams := c.BuildUrlQueryParams(params)
+	queryParams, err := encodeParams(c.charset(), c.BuildUrlQueryParams(params))
+	if err != nil {
+		return "", err
+	}
 	// 拼接到service url 作为查询参数
 	buildGetUrl, err := BuildGetUrl(request.ServiceUrl, queryParams)
 	if err != nil {
@@ -60,7 +106,60 @@ func (c *UiIcbcClient) BuildPostForm(request *ICBCRequest) (string, error) {
 	}
 
 	bodyParams := c.BuildBodyParams(params)
-	return BuildForm(buildGetUrl, bodyParams), nil
+	if opts == nil {
+		opts = c.FormOptions
+	}
+	// 表单页面为 UTF-8，GBK 签名时需要浏览器按 GBK 提交表单
+	if isGBK(c.charset()) {
+		gbkOpts := FormOptions{}
+		if opts != nil {
+			gbkOpts = *opts
+		}
+		gbkOpts.Charset = CharsetGBK
+		opts = &gbkOpts
+	}
+	return BuildFormWithOptions(buildGetUrl, bodyParams, opts)
+}
+
+// BuildRedirectURL UI类客户端 构建GET跳转URL，包括 biz_content 在内的所有签名参数都放在查询参数中
+//
+// 适用于需要单个跳转地址而不是自动提交表单的场景，如移动端 WebView。
+//
+// 参数:
+//   - request: 请求对象
+//
+// 返回值:
+//   - string: 跳转URL
+//   - error: 错误信息，URL超过 MaxRedirectURLLength 时返回包装 ErrURLTooLong 的错误，此时应改用 BuildPostForm
+func (c *UiIcbcClient) BuildRedirectURL(request *ICBCRequest) (string, error) {
+	if request == nil {
+		return "", fmt.Errorf("request cannot be nil")
+	}
+	if request.ServiceUrl == "" {
+		return "", fmt.Errorf("service url cannot be empty")
+	}
+
+	params, err := c.PrepareParams(request, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare params: %w", err)
+	}
+	params, err = encodeParams(c.charset(), params)
+	if err != nil {
+		return "", err
+	}
+	redirectUrl, err := BuildGetUrl(request.ServiceUrl, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to build get url: %w", err)
+	}
+
+	maxLength := c.MaxRedirectURLLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxRedirectURLLength
+	}
+	if len(redirectUrl) > maxLength {
+		return "", fmt.Errorf("%w: %d bytes exceeds limit of %d, use BuildPostForm instead", ErrURLTooLong, len(redirectUrl), maxLength)
+	}
+	return redirectUrl, nil
 }
 
 // BuildUrlQueryParams 构建URL查询参数
@@ -115,27 +214,63 @@ func (c *UiIcbcClient) BuildBodyParams(params *IcbcMap) *IcbcMap {
 //   - *IcbcMap: 准备好的参数
 //   - error: 错误信息
 func (c *DefaultClient) PrepareParams(request *ICBCRequest, msgId string) (*IcbcMap, error) {
+	params, _, err := c.PrepareSignedParams(request, msgId)
+	return params, err
+}
+
+// PrepareSignedParams 准备请求参数，同时返回待签名字符串，便于排查签名问题
+//
+// 参数:
+//   - request: 请求对象
+//   - msgId: 消息ID
+//
+// 返回值:
+//   - *IcbcMap: 准备好的参数
+//   - string: 待签名字符串，即 BuildOrderedSignStr 的结果
+//   - error: 错误信息
+func (c *DefaultClient) PrepareSignedParams(request *ICBCRequest, msgId string) (*IcbcMap, string, error) {
+	return c.prepareSignedParams(context.Background(), request, msgId)
+}
+
+// prepareSignedParams 准备请求参数，ctx 传递给 MsgIDGenerator
+//
+// 参数:
+//   - ctx: 上下文
+//   - request: 请求对象
+//   - msgId: 消息ID，为空时由 MsgIDGenerator 生成
+//
+// 返回值:
+//   - *IcbcMap: 准备好的参数
+//   - string: 待签名字符串
+//   - error: 错误信息
+func (c *DefaultClient) prepareSignedParams(ctx context.Context, request *ICBCRequest, msgId string) (*IcbcMap, string, error) {
 	// 验证请求对象
 	if request == nil {
-		return nil, fmt.Errorf("request cannot be nil")
+		return nil, "", fmt.Errorf("request cannot be nil")
 	}
 	if request.ServiceUrl == "" {
-		return nil, fmt.Errorf("service url cannot be empty")
+		return nil, "", fmt.Errorf("service url cannot be empty")
+	}
+	if err := validateCharset(c.Charset); err != nil {
+		return nil, "", err
 	}
 
-	//msgId 不传 默认用 UUID V7 生成
+	//msgId 不传 默认由 MsgIDGenerator 生成
 	if msgId == "" {
-		uuidV7, err := uuid.NewV7()
+		id, err := c.msgIDGenerator().NewMsgID(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate uuid: %w", err)
+			return nil, "", fmt.Errorf("failed to generate msg id: %w", err)
+		}
+		if id == "" {
+			return nil, "", fmt.Errorf("msg id generator returned empty msg id")
 		}
-		msgId = strings.ReplaceAll(uuidV7.String(), "-", "")
+		msgId = id
 	}
 
 	// 构建业务内容
 	bizContentStr, err := c.BuildBizContentStr(request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build biz content: %w", err)
+		return nil, "", fmt.Errorf("failed to build biz content: %w", err)
 	}
 
 	// 构建请求参数
@@ -144,25 +279,54 @@ func (c *DefaultClient) PrepareParams(request *ICBCRequest, msgId string) (*Icbc
 	params.Put("sign_type", c.SignType)
 	params.Put("msg_id", msgId)
 	params.Put("biz_content", bizContentStr)
-	params.Put("charset", "UTF-8")
+	params.Put("charset", c.charset())
 	params.Put("format", "json")
-	params.Put("timestamp", GetCurrentTime())
+	params.Put("timestamp", FormatTime(c.clock().Now()))
+
+	// 合并额外的公共参数，不允许覆盖 SDK 生成的参数
+	for k, v := range request.ExtraParams {
+		if k == "biz_content" || slices.Contains(ApiParamNames, k) {
+			return nil, "", fmt.Errorf("extra param %q conflicts with a reserved parameter", k)
+		}
+		params.Put(k, v)
+	}
 
 	// 解析URL获取路径
 	u, err := URL.Parse(request.ServiceUrl)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse service url: %w", err)
+		return nil, "", fmt.Errorf("failed to parse service url: %w", err)
 	}
 
-	// 构建签名字符串并签名
+	// 构建签名字符串并按请求字符集编码后签名
 	a := BuildOrderedSignStr(params, u.Path)
-	signStr, err := SignWithSHA256RSA(a, c.PrivateKey)
+	signData, err := encodeCharset(c.charset(), a)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode sign string: %w", err)
+	}
+	privateKey, err := c.signingKey()
+	if err != nil {
+		return nil, "", err
+	}
+	signStr, err := SignWithType(c.SignType, signData, privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign request: %w", err)
+		return nil, "", fmt.Errorf("failed to sign request: %w", err)
 	}
 
 	params.Put("sign", signStr)
-	return params, nil
+	return params, a, nil
+}
+
+// newUUIDString 生成去掉连字符的 UUID V7 字符串
+//
+// 返回值:
+//   - string: 32 位十六进制字符串
+//   - error: 错误信息
+func newUUIDString() (string, error) {
+	uuidV7, err := uuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	return strings.ReplaceAll(uuidV7.String(), "-", ""), nil
 }
 
 // BuildBizContentStr 构建业务内容字符串
@@ -201,109 +365,311 @@ func (c *DefaultClient) BuildBizContentStr(request *ICBCRequest) (string, error)
 //   - any: 响应对象
 //   - error: 错误信息
 func (c *DefaultClient) Execute(request *ICBCRequest, msgId string, res any) (any, error) {
+	return c.ExecuteWithContext(context.Background(), request, msgId, res)
+}
+
+// ExecuteWithContext 执行请求，支持通过 context 控制超时与取消
+//
+// 参数:
+//   - ctx: 上下文
+//   - request: 请求对象
+//   - msgId: 消息ID
+//   - res: 响应对象指针
+//
+// 返回值:
+//   - any: 响应对象
+//   - error: 错误信息
+func (c *DefaultClient) ExecuteWithContext(ctx context.Context, request *ICBCRequest, msgId string, res any) (any, error) {
+	if _, err := c.ExecuteCall(ctx, request, msgId, res); err != nil {
+		return "", err
+	}
+	return res, nil
+}
+
+// ExecuteCall 执行请求，并返回 msg_id、签名、耗时等调用信息
+//
+// 参数:
+//   - ctx: 上下文
+//   - request: 请求对象
+//   - msgId: 消息ID，为空时由 MsgIDGenerator 生成，可从返回的 CallResult 中获取
+//   - res: 响应对象指针
+//
+// 返回值:
+//   - *CallResult: 调用信息；参数签名完成后即使调用失败也会返回，便于记录 msg_id 排查问题
+//   - error: 错误信息
+func (c *DefaultClient) ExecuteCall(ctx context.Context, request *ICBCRequest, msgId string, res any) (*CallResult, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context cannot be nil")
+	}
 	// 验证请求对象
 	if request == nil {
-		return "", fmt.Errorf("request cannot be nil")
+		return nil, fmt.Errorf("request cannot be nil")
 	}
 	if request.ServiceUrl == "" {
-		return "", fmt.Errorf("service url cannot be empty")
+		return nil, fmt.Errorf("service url cannot be empty")
 	}
 
 	// 验证响应对象
 	if res == nil {
-		return "", fmt.Errorf("response object cannot be nil")
+		return nil, fmt.Errorf("response object cannot be nil")
 	}
 
 	// 准备请求参数
-	params, err := c.PrepareParams(request, msgId)
+	params, signStr, err := c.prepareSignedParams(ctx, request, msgId)
 	if err != nil {
-		return "", fmt.Errorf("failed to prepare params: %w", err)
+		return nil, fmt.Errorf("failed to prepare params: %w", err)
 	}
-
-	// 构建表单数据
-	formData := URL.Values{}
-	for k, v := range params.data {
-		formData.Set(k, v)
+	result := &CallResult{
+		MsgID:      params.Get("msg_id"),
+		Timestamp:  params.Get("timestamp"),
+		SignString: signStr,
+		Sign:       params.Get("sign"),
 	}
-	encodedData := formData.Encode()
 
 	// 创建HTTP请求
-	req, err := http.NewRequest("POST", request.ServiceUrl, strings.NewReader(encodedData))
+	req, err := newHTTPRequest(ctx, request, params, c.charset())
+	if err != nil {
+		return result, err
+	}
+
+	// 经过拦截器链发送请求并验签
+	call := &Call{
+		Request:     request,
+		Params:      params,
+		HTTPRequest: req,
+	}
+	start := time.Now()
+	err = chainInterceptors(c.Interceptors, c.invoke)(ctx, call)
+	result.Latency = time.Since(start)
+	result.Attempts = call.Attempts
+	result.StatusCode = call.StatusCode
+	result.BizContent = call.BizContent
+	c.logCall(ctx, call, result.Latency, err)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return result, err
 	}
 
-	// 设置请求头
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("APIGW-VERSION", version)
+	// 只从验签通过的 response_biz_content 解析目标对象
+	if !call.Verified {
+		return result, fmt.Errorf("%w: response was not verified", ErrSignatureVerification)
+	}
+	err = decodeResponse(call.BizContent, call.sign, res)
+	if err != nil {
+		return result, fmt.Errorf("failed to unmarshal response to target type: %w", err)
+	}
+
+	return result, nil
+}
 
-	// 使用自定义HTTP客户端或默认客户端
-	httpClient := c.HTTPClient
-	if httpClient == nil {
-		// 默认HTTP客户端配置，优化超时和连接池
-		httpClient = &http.Client{
-			Timeout: time.Second * 30, // 延长超时时间
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 20,
-				IdleConnTimeout:     90 * time.Second,
-			},
+// newHTTPRequest 按请求方法创建HTTP请求，GET 时参数放在查询字符串中，POST 时参数放在表单请求体中
+//
+// 参数:
+//   - ctx: 上下文
+//   - request: 请求对象，Method 为空时使用 POST
+//   - params: 已签名的请求参数
+//
+// 返回值:
+//   - *http.Request: HTTP请求
+//   - error: 错误信息
+func newHTTPRequest(ctx context.Context, request *ICBCRequest, params *IcbcMap, charset string) (*http.Request, error) {
+	params, err := encodeParams(charset, params)
+	if err != nil {
+		return nil, err
+	}
+	var req *http.Request
+	switch method := strings.ToUpper(request.Method); method {
+	case http.MethodGet:
+		getUrl, buildErr := BuildGetUrl(request.ServiceUrl, params)
+		if buildErr != nil {
+			return nil, fmt.Errorf("failed to build get url: %w", buildErr)
 		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, getUrl, nil)
+	case "", http.MethodPost:
+		// 构建表单数据
+		formData := URL.Values{}
+		for k, v := range params.All() {
+			formData.Set(k, v)
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, request.ServiceUrl, strings.NewReader(formData.Encode()))
+		if err == nil {
+			contentType := "application/x-www-form-urlencoded"
+			if isGBK(charset) {
+				contentType += "; charset=" + CharsetGBK
+			}
+			req.Header.Set("Content-Type", contentType)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported request method %q, expected GET or POST", request.Method)
 	}
-
-	// 发送HTTP请求
-	response, err := httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("APIGW-VERSION", version)
+	return req, nil
+}
 
-	// 确保响应体被关闭
-	defer func() {
-		if closeErr := response.Body.Close(); closeErr != nil {
-			fmt.Printf("warning: failed to close response body: %v\n", closeErr)
+// invoke 拦截器链末端：发送HTTP请求、读取响应体并验签
+//
+// 参数:
+//   - ctx: 上下文
+//   - call: 调用信息，执行后填充 Attempts、StatusCode、Body、DecodedBody、BizContent、Verified 和 VerifyErr
+//
+// 返回值:
+//   - error: 错误信息
+func (c *DefaultClient) invoke(ctx context.Context, call *Call) error {
+	// 发送HTTP请求，按重试策略重试
+	body, attempts, err := c.send(ctx, call.HTTPRequest, c.RetryPolicy.attempts(call.Request))
+	call.Attempts = attempts
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			call.StatusCode = statusErr.StatusCode
 		}
-	}()
-
-	// 检查HTTP状态码
-	if response.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d, response: %s", response.StatusCode, response.Status)
+		return err
 	}
-
-	// 读取响应体
-	body, err := io.ReadAll(response.Body)
+	// 非 200 的响应已由 doRequest 转为 StatusError
+	call.StatusCode = http.StatusOK
+	// call.Body 记录工行签名的原始字节，call.DecodedBody 记录解码为 UTF-8 的响应体
+	call.Body = body
+	decoded, err := decodeCharset(c.charset(), body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return err
 	}
+	call.DecodedBody = decoded
 
-	// 验证响应是否为有效JSON
-	isValid := json.Valid(body)
-	if !isValid {
-		return "", fmt.Errorf("invalid response json body: %s", string(body))
+	bizContent, sign, err := c.verifyResponse(body)
+	if err != nil {
+		if errors.Is(err, ErrSignatureVerification) {
+			call.VerifyErr = err
+		}
+		return err
 	}
+	call.Verified = true
+	call.BizContent = bizContent
+	call.sign = sign
+	return nil
+}
+
+// VerifyResponse 校验工行响应体的签名
+//
+// 参数:
+//   - body: 原始响应体 {"response_biz_content":{...},"sign":"..."}，为客户端字符集的原始字节
+//
+// 返回值:
+//   - json.RawMessage: 验签通过并解码为 UTF-8 的 response_biz_content 原文，UTF-8 时引用 body 中的字节
+//   - error: 错误信息，验签失败时包装 ErrSignatureVerification
+func (c *DefaultClient) VerifyResponse(body []byte) (json.RawMessage, error) {
+	bizContent, _, err := c.verifyResponse(body)
+	return bizContent, err
+}
 
-	// 解析ICBC响应
-	var icbcResponse IcbcResponse
-	err = json.Unmarshal(body, &icbcResponse)
+// verifyResponse 在原始字节上验签响应，验签通过后再将 response_biz_content 解码为 UTF-8，同时返回响应签名
+func (c *DefaultClient) verifyResponse(body []byte) (json.RawMessage, string, error) {
+	// 单次扫描定位 response_biz_content 原文和 sign，不解析业务内容
+	env, err := scanResponse(body, c.charset())
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal icbc response: %w", err)
+		return nil, "", err
 	}
 
 	// 验证签名
-	rawBizContent := string(icbcResponse.ResponseBizContent)
-	sign := icbcResponse.Sign
-	pass, err := VerifySHA1RSA(rawBizContent, sign, c.IcbcPublicKey)
+	if err := c.verifySignature(VerifySHA1RSA, string(env.bizContent), env.sign); err != nil {
+		return nil, "", err
+	}
+	bizContent, err := decodeCharset(c.charset(), env.bizContent)
 	if err != nil {
-		return "", fmt.Errorf("failed to verify signature: %w", err)
+		return nil, "", err
+	}
+	return bizContent, env.sign, nil
+}
+
+// httpClient 返回自定义HTTP客户端或默认客户端
+//
+// 返回值:
+//   - *http.Client: HTTP客户端
+func (c *DefaultClient) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
 	}
-	if !pass {
-		return "", fmt.Errorf("signature verification failed")
+	return defaultHTTPClient
+}
+
+// maxResponseSize 返回响应体的最大字节数，未配置时为 DefaultMaxResponseSize
+func (c *DefaultClient) maxResponseSize() int64 {
+	if c.MaxResponseSize > 0 {
+		return c.MaxResponseSize
 	}
+	return DefaultMaxResponseSize
+}
 
-	// 解析响应体到目标对象
-	err = json.Unmarshal(body, res)
+// send 发送HTTP请求并读取响应体，失败时按重试策略重试
+//
+// 每次重试都发送完全相同的请求体，msg_id、timestamp 和 sign 均保持不变，
+// 工行网关可据此识别重复请求。
+//
+// 参数:
+//   - ctx: 上下文
+//   - req: HTTP请求
+//   - maxAttempts: 最大尝试次数
+//
+// 返回值:
+//   - []byte: 响应体
+//   - int: 实际发送次数
+//   - error: 错误信息
+func (c *DefaultClient) send(ctx context.Context, req *http.Request, maxAttempts int) ([]byte, int, error) {
+	httpClient := c.httpClient()
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			attemptReq = req.Clone(ctx)
+			if req.GetBody != nil {
+				bodyReader, err := req.GetBody()
+				if err != nil {
+					return nil, attempt - 1, fmt.Errorf("failed to rewind request body: %w", err)
+				}
+				attemptReq.Body = bodyReader
+			}
+		}
+
+		body, err := c.doRequest(httpClient, attemptReq)
+		if err == nil {
+			return body, attempt, nil
+		}
+		if attempt >= maxAttempts || ctx.Err() != nil || !c.RetryPolicy.retryable(err) {
+			return nil, attempt, err
+		}
+		if sleepErr := sleepContext(ctx, c.RetryPolicy.backoff().Delay(attempt-1)); sleepErr != nil {
+			return nil, attempt, fmt.Errorf("retry aborted after %d attempts: %w, last error: %w", attempt, sleepErr, err)
+		}
+	}
+}
+
+// doRequest 发送一次HTTP请求并读取响应体
+//
+// 参数:
+//   - httpClient: HTTP客户端
+//   - req: HTTP请求
+//
+// 返回值:
+//   - []byte: 响应体
+//   - error: 错误信息，HTTP状态码非 200 时为 *StatusError
+func (c *DefaultClient) doRequest(httpClient *http.Client, req *http.Request) ([]byte, error) {
+	response, err := httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal response to target type: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
-	return res, nil
+	// 确保响应体被关闭
+	defer func() {
+		if closeErr := response.Body.Close(); closeErr != nil {
+			c.logger().Warn("failed to close response body", slog.String("error", closeErr.Error()))
+		}
+	}()
+
+	// 检查HTTP状态码
+	if response.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: response.StatusCode, Status: response.Status}
+	}
+
+	// 读取响应体，限制最大长度
+	return readResponseBody(response.Body, response.ContentLength, c.maxResponseSize())
 }
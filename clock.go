@@ -0,0 +1,68 @@
+package icbc_api_sdk_go
+
+import (
+	"context"
+	"time"
+)
+
+// MsgIDGenerator msg_id 生成器，可用于将 msg_id 与调用方的链路追踪ID关联
+type MsgIDGenerator interface {
+	// NewMsgID 生成新的 msg_id，ctx 为本次调用的上下文
+	NewMsgID(ctx context.Context) (string, error)
+}
+
+// MsgIDGeneratorFunc 函数形式的 MsgIDGenerator
+type MsgIDGeneratorFunc func(ctx context.Context) (string, error)
+
+// NewMsgID 调用 f(ctx)
+func (f MsgIDGeneratorFunc) NewMsgID(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// UUIDMsgIDGenerator 默认的 msg_id 生成器，生成去掉连字符的 UUID V7
+var UUIDMsgIDGenerator MsgIDGenerator = MsgIDGeneratorFunc(func(context.Context) (string, error) {
+	return newUUIDString()
+})
+
+// Clock 时钟，用于生成请求的 timestamp 参数
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc 函数形式的 Clock
+type ClockFunc func() time.Time
+
+// Now 调用 f()
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock 默认时钟，返回系统当前时间
+var SystemClock Clock = ClockFunc(time.Now)
+
+// FixedClock 返回固定时间的时钟，用于测试中生成可重现的签名参数
+//
+// 参数:
+//   - t: 固定时间
+//
+// 返回值:
+//   - Clock: 时钟
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// msgIDGenerator 返回配置的 msg_id 生成器，未配置时使用 UUIDMsgIDGenerator
+func (c *DefaultClient) msgIDGenerator() MsgIDGenerator {
+	if c.MsgIDGenerator != nil {
+		return c.MsgIDGenerator
+	}
+	return UUIDMsgIDGenerator
+}
+
+// clock 返回配置的时钟，未配置时使用 SystemClock
+func (c *DefaultClient) clock() Clock {
+	if c.Clock != nil {
+		return c.Clock
+	}
+	return SystemClock
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+)
+
+// requestFlags sign、call、form 共用的请求参数
+type requestFlags struct {
+	config  string
+	url     string
+	biz     string
+	bizFile string
+	msgId   string
+}
+
+// register 注册请求参数
+func (f *requestFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.config, "config", "", "客户端配置文件(YAML、JSON 或 TOML)，为空时从 ICBC_ 开头的环境变量读取")
+	fs.StringVar(&f.url, "url", "", "接口地址(service url)，相对路径时拼接到配置的 base_url 或 environment 对应的网关地址")
+	fs.StringVar(&f.biz, "biz", "", "biz_content JSON")
+	fs.StringVar(&f.bizFile, "biz-file", "", "biz_content JSON 文件，\"-\" 表示标准输入")
+	fs.StringVar(&f.msgId, "msg-id", "", "msg_id，为空时自动生成")
+}
+
+// build 创建客户端和请求对象
+//
+// 参数:
+//   - stdin: 标准输入
+//
+// 返回值:
+//   - *icbc.DefaultClient: 客户端
+//   - *icbc.ICBCRequest: 请求对象
+//   - error: 错误信息
+func (f *requestFlags) build(stdin io.Reader) (*icbc.DefaultClient, *icbc.ICBCRequest, error) {
+	client, err := loadClient(f.config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.url == "" {
+		return nil, nil, fmt.Errorf("-url is required")
+	}
+	serviceUrl, err := client.ServiceURL(f.url)
+	if err != nil {
+		return nil, nil, err
+	}
+	biz, err := readInput(f.biz, f.bizFile, stdin)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read biz_content: %w", err)
+	}
+	request := &icbc.ICBCRequest{ServiceUrl: serviceUrl, Method: "POST"}
+	if len(bytes.TrimSpace(biz)) > 0 {
+		if !json.Valid(biz) {
+			return nil, nil, fmt.Errorf("biz_content is not valid JSON")
+		}
+		request.BizContent = json.RawMessage(biz)
+	}
+	return client, request, nil
+}
+
+// runSign 打印有序待签名字符串和签名
+func runSign(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	var f requestFlags
+	f.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	client, request, err := f.build(stdin)
+	if err != nil {
+		return err
+	}
+	params, signStr, err := client.PrepareSignedParams(request, f.msgId)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "sign string:\n%s\n\nsign:\n%s\n", signStr, params.Get("sign"))
+	return nil
+}
+
+// runVerify 校验响应体或异步通知的签名
+func runVerify(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	config := fs.String("config", "", "客户端配置文件(YAML、JSON 或 TOML)，为空时从 ICBC_ 开头的环境变量读取")
+	bodyFile := fs.String("body", "-", "响应体或异步通知表单文件，\"-\" 表示标准输入")
+	notifyPath := fs.String("notify-path", "", "notify_url 的路径，设置时按异步通知(表单格式)验签")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	client, err := loadClient(*config)
+	if err != nil {
+		return err
+	}
+	body, err := readInput("", *bodyFile, stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+	body = bytes.TrimSpace(body)
+
+	if *notifyPath != "" {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return fmt.Errorf("failed to parse notify form: %w", err)
+		}
+		if _, err := client.VerifyNotify(*notifyPath, form); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "signature OK")
+		return writeJSON(stdout, []byte(form.Get("biz_content")))
+	}
+
+	bizContent, err := client.VerifyResponse(body)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "signature OK")
+	return writeJSON(stdout, bizContent)
+}
+
+// runCall 发送请求并打印验签通过的响应
+func runCall(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("call", flag.ContinueOnError)
+	var f requestFlags
+	f.register(fs)
+	timeout := fs.Duration("timeout", 30*time.Second, "请求超时时间")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	client, request, err := f.build(stdin)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	var res json.RawMessage
+	if _, err := client.ExecuteWithContext(ctx, request, f.msgId, &res); err != nil {
+		return err
+	}
+	return writeJSON(stdout, res)
+}
+
+// runForm 生成 POST 表单页面
+func runForm(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("form", flag.ContinueOnError)
+	var f requestFlags
+	f.register(fs)
+	out := fs.String("out", "-", "输出文件，\"-\" 表示标准输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	client, request, err := f.build(stdin)
+	if err != nil {
+		return err
+	}
+	uiClient := &icbc.UiIcbcClient{DefaultClient: *client}
+	form, err := uiClient.BuildPostForm(request)
+	if err != nil {
+		return err
+	}
+	if *out == "-" {
+		_, err = io.WriteString(stdout, form)
+		return err
+	}
+	if err := os.WriteFile(*out, []byte(form), 0o644); err != nil {
+		return fmt.Errorf("failed to write form: %w", err)
+	}
+	fmt.Fprintf(stdout, "form written to %s\n", *out)
+	return nil
+}
+
+// writeJSON 格式化输出 JSON，无法格式化时原样输出
+func writeJSON(w io.Writer, data []byte) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		_, err = fmt.Fprintln(w, strings.TrimSpace(string(data)))
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbcconfig"
+)
+
+// loadConfig 读取客户端配置，支持 YAML、JSON、TOML 配置文件，未指定文件时从 ICBC_ 开头的环境变量读取
+//
+// 参数:
+//   - path: 配置文件路径，可为空
+//
+// 返回值:
+//   - *icbc.Config: 校验后的配置
+//   - error: 错误信息
+func loadConfig(path string) (*icbc.Config, error) {
+	if path == "" {
+		cfg, err := icbc.LoadConfigFromEnv("")
+		if err != nil {
+			return nil, fmt.Errorf("-config is not set, loading from environment: %w", err)
+		}
+		return cfg, nil
+	}
+	return icbcconfig.Load(path)
+}
+
+// loadClient 读取客户端配置并创建客户端
+//
+// 参数:
+//   - path: 配置文件路径，可为空
+//
+// 返回值:
+//   - *icbc.DefaultClient: 客户端
+//   - error: 错误信息
+func loadClient(path string) (*icbc.DefaultClient, error) {
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.NewClient()
+}
+
+// readInput 读取输入内容，优先使用直接传入的值，其次读取文件，文件为 "-" 时读取标准输入
+//
+// 参数:
+//   - value: 直接传入的值
+//   - file: 文件路径
+//   - stdin: 标准输入
+//
+// 返回值:
+//   - []byte: 输入内容
+//   - error: 错误信息
+func readInput(value, file string, stdin io.Reader) ([]byte, error) {
+	switch {
+	case value != "":
+		return []byte(value), nil
+	case file == "-":
+		return io.ReadAll(stdin)
+	case file != "":
+		return os.ReadFile(file)
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,24 @@
+module github.com/ljjdev/icbc-api-sdk-go/cmd/icbc
+
+go 1.25
+
+require (
+	github.com/ljjdev/icbc-api-sdk-go v0.0.0
+	github.com/ljjdev/icbc-api-sdk-go/icbcconfig v0.0.0
+	github.com/tjfoc/gmsm v1.4.1
+)
+
+require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/crypto v0.0.0-20201012173705-84dcc777aaee // indirect
+	golang.org/x/sys v0.0.0-20200930185726-fdedc70b468f // indirect
+	golang.org/x/text v0.30.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// 命令行工具与 SDK 在同一仓库中开发，使用本地的 SDK 源码
+replace (
+	github.com/ljjdev/icbc-api-sdk-go => ../..
+	github.com/ljjdev/icbc-api-sdk-go/icbcconfig => ../../icbcconfig
+)
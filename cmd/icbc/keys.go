@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/tjfoc/gmsm/sm2"
+	gmx509 "github.com/tjfoc/gmsm/x509"
+)
+
+const keysUsage = `icbc keys - 商户密钥管理
+
+用法:
+  icbc keys <command> [flags]
+
+命令:
+  generate      生成 RSA-2048 或 SM2 密钥对
+  convert       在 PKCS#1、PKCS#8(PEM) 和无标记的 Base64 之间转换密钥格式
+  fingerprint   打印公钥指纹
+  selftest      校验私钥与已上传的公钥是否匹配
+`
+
+// 密钥输出格式
+const (
+	formatBase64 = "base64" // 不含 PEM 标记的 Base64，私钥为 PKCS#8，公钥为 PKIX，即 SDK 和工行开放平台使用的格式
+	formatPKCS8  = "pkcs8"  // PEM 格式的 PKCS#8 私钥或 PKIX 公钥
+	formatPKCS1  = "pkcs1"  // PEM 格式的 PKCS#1 私钥或公钥，仅支持 RSA
+)
+
+// keyPair 解析后的密钥，私钥为空时只包含公钥
+type keyPair struct {
+	private any // *rsa.PrivateKey 或 *sm2.PrivateKey
+	public  any // *rsa.PublicKey 或 *sm2.PublicKey
+}
+
+// runKeys 执行密钥管理子命令
+func runKeys(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stdout, keysUsage)
+		return flag.ErrHelp
+	}
+	commands := map[string]func(args []string, stdin io.Reader, stdout io.Writer) error{
+		"generate":    runKeysGenerate,
+		"convert":     runKeysConvert,
+		"fingerprint": runKeysFingerprint,
+		"selftest":    runKeysSelftest,
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown keys command %q\n\n%s", args[0], keysUsage)
+	}
+	return command(args[1:], stdin, stdout)
+}
+
+// runKeysGenerate 生成密钥对
+func runKeysGenerate(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("keys generate", flag.ContinueOnError)
+	keyType := fs.String("type", "rsa", "密钥类型: rsa 或 sm2")
+	bits := fs.Int("bits", 2048, "RSA 密钥长度")
+	format := fs.String("format", formatBase64, "输出格式: base64、pkcs8 或 pkcs1(仅 RSA)")
+	out := fs.String("out", "", "输出文件前缀，生成 <out>_private.txt 和 <out>_public.txt；为空时输出到标准输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var pair keyPair
+	switch strings.ToLower(*keyType) {
+	case "rsa":
+		if *bits < 2048 {
+			return fmt.Errorf("rsa key size %d is too small, at least 2048", *bits)
+		}
+		key, err := rsa.GenerateKey(rand.Reader, *bits)
+		if err != nil {
+			return fmt.Errorf("failed to generate rsa key: %w", err)
+		}
+		pair = keyPair{private: key, public: &key.PublicKey}
+	case "sm2":
+		key, err := sm2.GenerateKey(rand.Reader)
+		if err != nil {
+			return fmt.Errorf("failed to generate sm2 key: %w", err)
+		}
+		pair = keyPair{private: key, public: &key.PublicKey}
+	default:
+		return fmt.Errorf("unsupported key type %q", *keyType)
+	}
+
+	privateText, err := encodePrivateKey(pair.private, *format)
+	if err != nil {
+		return err
+	}
+	publicText, err := encodePublicKey(pair.public, *format)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		fmt.Fprintf(stdout, "private key:\n%s\n\npublic key:\n%s\n", privateText, publicText)
+		return nil
+	}
+	privateFile, publicFile := *out+"_private.txt", *out+"_public.txt"
+	if err := os.WriteFile(privateFile, []byte(privateText+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(publicFile, []byte(publicText+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+	fmt.Fprintf(stdout, "private key written to %s\npublic key written to %s\n", privateFile, publicFile)
+	return nil
+}
+
+// runKeysConvert 转换密钥格式
+func runKeysConvert(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("keys convert", flag.ContinueOnError)
+	in := fs.String("in", "-", "输入密钥文件，支持 PEM 或 Base64，\"-\" 表示标准输入")
+	to := fs.String("to", formatBase64, "输出格式: base64、pkcs8 或 pkcs1(仅 RSA)")
+	public := fs.Bool("public", false, "输入为私钥时只输出对应的公钥")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pair, err := readKeyFile(*in, stdin)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	if pair.private != nil && !*public {
+		text, err = encodePrivateKey(pair.private, *to)
+	} else {
+		text, err = encodePublicKey(pair.public, *to)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, text)
+	return nil
+}
+
+// runKeysFingerprint 打印公钥指纹
+func runKeysFingerprint(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("keys fingerprint", flag.ContinueOnError)
+	in := fs.String("in", "-", "密钥文件，私钥时打印对应公钥的指纹，\"-\" 表示标准输入")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pair, err := readKeyFile(*in, stdin)
+	if err != nil {
+		return err
+	}
+	der, err := marshalPublicKey(pair.public)
+	if err != nil {
+		return err
+	}
+	sha256Sum := sha256.Sum256(der)
+	md5Sum := md5.Sum(der)
+	fmt.Fprintf(stdout, "type:   %s\nSHA256: %s\nMD5:    %s\n", keyTypeName(pair.public), colonHex(sha256Sum[:]), colonHex(md5Sum[:]))
+	return nil
+}
+
+// runKeysSelftest 使用私钥签名并用公钥验签，确认两者匹配
+func runKeysSelftest(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("keys selftest", flag.ContinueOnError)
+	privateFile := fs.String("private", "", "商户私钥文件")
+	publicFile := fs.String("public", "", "已上传到工行开放平台的商户公钥文件")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *privateFile == "" || *publicFile == "" {
+		return fmt.Errorf("-private and -public are required")
+	}
+	privatePair, err := readKeyFile(*privateFile, stdin)
+	if err != nil {
+		return fmt.Errorf("private key: %w", err)
+	}
+	if privatePair.private == nil {
+		return fmt.Errorf("private key: %s does not contain a private key", *privateFile)
+	}
+	publicPair, err := readKeyFile(*publicFile, stdin)
+	if err != nil {
+		return fmt.Errorf("public key: %w", err)
+	}
+
+	const message = "icbc-api-sdk-go key self test"
+	switch privateKey := privatePair.private.(type) {
+	case *rsa.PrivateKey:
+		publicKey, ok := publicPair.public.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type mismatch: private key is RSA, public key is %s", keyTypeName(publicPair.public))
+		}
+		// 使用 SDK 的签名和验签函数，确保与实际请求使用相同的密钥格式和算法
+		privateText, err := encodePrivateKey(privateKey, formatBase64)
+		if err != nil {
+			return err
+		}
+		publicText, err := encodePublicKey(publicKey, formatBase64)
+		if err != nil {
+			return err
+		}
+		signature, err := icbc.SignWithSHA256RSA(message, privateText)
+		if err != nil {
+			return fmt.Errorf("failed to sign: %w", err)
+		}
+		if _, err := icbc.VerifySHA256RSA(message, signature, publicText); err != nil {
+			return fmt.Errorf("private key does not match public key: %w", err)
+		}
+	case *sm2.PrivateKey:
+		publicKey, ok := publicPair.public.(*sm2.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type mismatch: private key is SM2, public key is %s", keyTypeName(publicPair.public))
+		}
+		signature, err := privateKey.Sign(rand.Reader, []byte(message), nil)
+		if err != nil {
+			return fmt.Errorf("failed to sign: %w", err)
+		}
+		if !publicKey.Verify([]byte(message), signature) {
+			return fmt.Errorf("private key does not match public key")
+		}
+	default:
+		return fmt.Errorf("unsupported private key type %T", privatePair.private)
+	}
+	fmt.Fprintln(stdout, "self test OK: private key matches public key")
+	return nil
+}
+
+// readKeyFile 读取并解析密钥文件
+//
+// 参数:
+//   - path: 文件路径，"-" 表示标准输入
+//   - stdin: 标准输入
+//
+// 返回值:
+//   - *keyPair: 密钥
+//   - error: 错误信息
+func readKeyFile(path string, stdin io.Reader) (*keyPair, error) {
+	data, err := readInput("", path, stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	return parseKey(data)
+}
+
+// parseKey 自动识别并解析 PEM 或 Base64 格式的 RSA/SM2 私钥或公钥
+//
+// 参数:
+//   - data: 密钥内容
+//
+// 返回值:
+//   - *keyPair: 密钥
+//   - error: 错误信息
+func parseKey(data []byte) (*keyPair, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("key is empty")
+	}
+
+	var der []byte
+	if block, _ := pem.Decode(data); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(data)), ""))
+		if err != nil {
+			return nil, fmt.Errorf("key is neither PEM nor base64: %w", err)
+		}
+		der = decoded
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return &keyPair{private: rsaKey, public: &rsaKey.PublicKey}, nil
+	}
+	if key, err := gmx509.ParsePKCS8UnecryptedPrivateKey(der); err == nil {
+		return &keyPair{private: key, public: &key.PublicKey}, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return &keyPair{private: key, public: &key.PublicKey}, nil
+	}
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+		return &keyPair{public: rsaKey}, nil
+	}
+	if key, err := gmx509.ParseSm2PublicKey(der); err == nil {
+		return &keyPair{public: key}, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return &keyPair{public: key}, nil
+	}
+	return nil, errors.New("unrecognized key: expected RSA (PKCS#1/PKCS#8/PKIX) or SM2 (PKCS#8/PKIX)")
+}
+
+// encodePrivateKey 按指定格式编码私钥
+func encodePrivateKey(key any, format string) (string, error) {
+	var der []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if format == formatPKCS1 {
+			return encodePEM("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(k)), nil
+		}
+		der, err = x509.MarshalPKCS8PrivateKey(k)
+	case *sm2.PrivateKey:
+		if format == formatPKCS1 {
+			return "", fmt.Errorf("pkcs1 format only supports RSA keys")
+		}
+		der, err = gmx509.MarshalSm2UnecryptedPrivateKey(k)
+	default:
+		return "", fmt.Errorf("unsupported private key type %T", key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return encodeDER("PRIVATE KEY", der, format)
+}
+
+// encodePublicKey 按指定格式编码公钥
+func encodePublicKey(key any, format string) (string, error) {
+	if rsaKey, ok := key.(*rsa.PublicKey); ok && format == formatPKCS1 {
+		return encodePEM("RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(rsaKey)), nil
+	}
+	if format == formatPKCS1 {
+		return "", fmt.Errorf("pkcs1 format only supports RSA keys")
+	}
+	der, err := marshalPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return encodeDER("PUBLIC KEY", der, format)
+}
+
+// marshalPublicKey 将公钥编码为 PKIX DER
+func marshalPublicKey(key any) ([]byte, error) {
+	var der []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		der, err = x509.MarshalPKIXPublicKey(k)
+	case *sm2.PublicKey:
+		der, err = gmx509.MarshalSm2PublicKey(k)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return der, nil
+}
+
+// encodeDER 按格式输出 DER，base64 为不含标记的单行 Base64，pkcs8 为 PEM
+func encodeDER(pemType string, der []byte, format string) (string, error) {
+	switch format {
+	case formatBase64:
+		return base64.StdEncoding.EncodeToString(der), nil
+	case formatPKCS8:
+		return encodePEM(pemType, der), nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, expected base64, pkcs8 or pkcs1", format)
+	}
+}
+
+// encodePEM 编码为 PEM 字符串
+func encodePEM(pemType string, der []byte) string {
+	return strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: der})))
+}
+
+// keyTypeName 返回公钥类型名称
+func keyTypeName(key any) string {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%d", k.N.BitLen())
+	case *sm2.PublicKey:
+		return "SM2"
+	default:
+		return fmt.Sprintf("%T", key)
+	}
+}
+
+// colonHex 以冒号分隔的十六进制字符串
+func colonHex(b []byte) string {
+	parts := make([]string, len(b))
+	for i := range b {
+		parts[i] = hex.EncodeToString(b[i : i+1])
+	}
+	return strings.Join(parts, ":")
+}
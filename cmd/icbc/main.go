@@ -0,0 +1,81 @@
+// icbc 命令行工具，基于 icbc-api-sdk-go 提供签名、验签、接口调用和表单生成功能，便于排查工行接口问题。
+//
+// 用法:
+//
+//	icbc sign   -config client.json -url <service url> -biz '{"mer_id":"..."}'
+//	icbc verify -config client.json -body response.json
+//	icbc verify -config client.json -body notify.txt -notify-path /icbc/notify
+//	icbc call   -config client.json -url <service url> -biz-file biz.json
+//	icbc form   -config client.json -url <service url> -biz-file biz.json -out form.html
+//	icbc keys   generate -type rsa -out merchant
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `icbc - 工行 API 命令行工具
+
+用法:
+  icbc <command> [flags]
+
+命令:
+  sign     打印有序待签名字符串和签名
+  verify   校验响应体或异步通知的签名
+  call     发送 biz_content 到接口地址并打印响应
+  form     生成 BuildPostForm 的 HTML 表单
+  keys     生成、转换、查看商户密钥
+
+使用 "icbc <command> -h" 查看命令参数。
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 执行子命令
+//
+// 参数:
+//   - args: 命令行参数，不含程序名
+//   - stdin: 标准输入
+//   - stdout: 标准输出
+//   - stderr: 标准错误
+//
+// 返回值:
+//   - int: 进程退出码
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	commands := map[string]func(args []string, stdin io.Reader, stdout io.Writer) error{
+		"sign":   runSign,
+		"verify": runVerify,
+		"call":   runCall,
+		"form":   runForm,
+		"keys":   runKeys,
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+			fmt.Fprint(stdout, usage)
+			return 0
+		}
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := command(args[1:], stdin, stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "icbc %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,347 @@
+package icbc_api_sdk_go
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	URL "net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 配置默认值
+const (
+	DefaultSignType       = SignTypeRSA2
+	DefaultTimeout        = 30 * time.Second
+	DefaultConnectTimeout = 10 * time.Second
+)
+
+// DefaultEnvPrefix LoadConfigFromEnv 默认的环境变量前缀
+const DefaultEnvPrefix = "ICBC_"
+
+// Config 客户端配置
+//
+// 密钥既可以直接配置内容，也可以配置文件路径，两者同时配置时以直接配置的内容为准。
+type Config struct {
+	AppID             string   `json:"app_id" yaml:"app_id" toml:"app_id"`
+	MerIDs            []string `json:"mer_ids" yaml:"mer_ids" toml:"mer_ids"`                                        // 应用对应的商户编号，用于 Registry 按 mer_id 查找客户端
+	SignType          string   `json:"sign_type" yaml:"sign_type" toml:"sign_type"`                                  // 签名类型，默认 RSA2
+	Charset           string   `json:"charset" yaml:"charset" toml:"charset"`                                        // 字符集，UTF-8(默认) 或 GBK
+	PrivateKey        string   `json:"private_key" yaml:"private_key" toml:"private_key"`                            // 商户私钥，Base64 编码的 PKCS8 私钥
+	PrivateKeyFile    string   `json:"private_key_file" yaml:"private_key_file" toml:"private_key_file"`             // 商户私钥文件路径
+	IcbcPublicKey     string   `json:"icbc_public_key" yaml:"icbc_public_key" toml:"icbc_public_key"`                // 工行网关公钥
+	IcbcPublicKeyFile string   `json:"icbc_public_key_file" yaml:"icbc_public_key_file" toml:"icbc_public_key_file"` // 工行网关公钥文件路径
+	Environment       string   `json:"environment" yaml:"environment" toml:"environment"`                            // 网关环境，production 或 sandbox
+	BaseURL           string   `json:"base_url" yaml:"base_url" toml:"base_url"`                                     // 网关地址，优先于 environment，如本地模拟网关地址
+	Timeout           string   `json:"timeout" yaml:"timeout" toml:"timeout"`                                        // 单次请求超时，time.ParseDuration 格式如 "30s"，默认 30s
+	ConnectTimeout    string   `json:"connect_timeout" yaml:"connect_timeout" toml:"connect_timeout"`                // 建立连接超时，默认 10s
+	Proxy             string   `json:"proxy" yaml:"proxy" toml:"proxy"`                                              // 代理地址，为空时使用 HTTP_PROXY 等环境变量
+}
+
+// ConfigError 配置错误，Field 为配置文件中的字段名
+type ConfigError struct {
+	Field string
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config %s: %v", e.Field, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigDecoder 将配置文件内容解析到 cfg，配置中存在 Config 没有的键时应返回指明该键的错误
+type ConfigDecoder func(r io.Reader, cfg *Config) error
+
+// DecodeJSONConfig 解析 JSON 配置，存在未知的键时返回错误
+//
+// 参数:
+//   - r: 配置内容
+//   - cfg: 解析结果
+//
+// 返回值:
+//   - error: 错误信息
+func DecodeJSONConfig(r io.Reader, cfg *Config) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("unexpected data after config object")
+	}
+	return nil
+}
+
+// LoadConfig 读取 JSON 配置文件，并加载密钥文件、校验配置
+//
+// YAML 和 TOML 配置文件使用 icbcconfig 模块的 Load 读取，避免 SDK 引入解析库依赖。
+// 配置文件中的相对密钥路径以配置文件所在目录为基准。
+//
+// 参数:
+//   - path: 配置文件路径，扩展名为 .json
+//
+// 返回值:
+//   - *Config: 校验后的配置，密钥文件内容已读取到 PrivateKey 和 IcbcPublicKey
+//   - error: 错误信息，字段错误为 *ConfigError
+func LoadConfig(path string) (*Config, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".json" {
+		return nil, fmt.Errorf("unsupported config format %q, expected .json; use the icbcconfig module for .yaml, .yml and .toml", ext)
+	}
+	return LoadConfigWithDecoder(path, DecodeJSONConfig)
+}
+
+// LoadConfigWithDecoder 使用指定的解析函数读取配置文件，并加载密钥文件、校验配置
+//
+// 配置文件中的相对密钥路径以配置文件所在目录为基准。
+//
+// 参数:
+//   - path: 配置文件路径
+//   - decode: 配置解析函数
+//
+// 返回值:
+//   - *Config: 校验后的配置，密钥文件内容已读取到 PrivateKey 和 IcbcPublicKey
+//   - error: 错误信息，字段错误为 *ConfigError
+func LoadConfigWithDecoder(path string, decode ConfigDecoder) (*Config, error) {
+	if decode == nil {
+		return nil, fmt.Errorf("config decoder cannot be nil")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	defer file.Close()
+	cfg := &Config{}
+	if err := decode(file, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for _, file := range []*string{&cfg.PrivateKeyFile, &cfg.IcbcPublicKeyFile} {
+		if *file != "" && !filepath.IsAbs(*file) {
+			*file = filepath.Join(dir, *file)
+		}
+	}
+	if err := cfg.load(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadConfigFromEnv 从环境变量读取配置，并加载密钥文件、校验配置
+//
+// 以默认前缀 ICBC_ 为例，读取的环境变量为:
+//   - ICBC_APP_ID、ICBC_SIGN_TYPE、ICBC_CHARSET、ICBC_MER_IDS(多个商户编号以逗号分隔)
+//   - ICBC_PRIVATE_KEY 或 ICBC_PRIVATE_KEY_FILE
+//   - ICBC_PUBLIC_KEY 或 ICBC_PUBLIC_KEY_FILE(工行网关公钥)
+//   - ICBC_ENVIRONMENT、ICBC_BASE_URL、ICBC_TIMEOUT、ICBC_CONNECT_TIMEOUT、ICBC_PROXY
+//
+// 参数:
+//   - prefix: 环境变量前缀，为空时使用 DefaultEnvPrefix
+//
+// 返回值:
+//   - *Config: 校验后的配置
+//   - error: 错误信息，字段错误为 *ConfigError
+func LoadConfigFromEnv(prefix string) (*Config, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	cfg := &Config{
+		AppID:             os.Getenv(prefix + "APP_ID"),
+		MerIDs:            splitList(os.Getenv(prefix + "MER_IDS")),
+		SignType:          os.Getenv(prefix + "SIGN_TYPE"),
+		Charset:           os.Getenv(prefix + "CHARSET"),
+		PrivateKey:        os.Getenv(prefix + "PRIVATE_KEY"),
+		PrivateKeyFile:    os.Getenv(prefix + "PRIVATE_KEY_FILE"),
+		IcbcPublicKey:     os.Getenv(prefix + "PUBLIC_KEY"),
+		IcbcPublicKeyFile: os.Getenv(prefix + "PUBLIC_KEY_FILE"),
+		Environment:       os.Getenv(prefix + "ENVIRONMENT"),
+		BaseURL:           os.Getenv(prefix + "BASE_URL"),
+		Timeout:           os.Getenv(prefix + "TIMEOUT"),
+		ConnectTimeout:    os.Getenv(prefix + "CONNECT_TIMEOUT"),
+		Proxy:             os.Getenv(prefix + "PROXY"),
+	}
+	if err := cfg.load(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// splitList 拆分逗号分隔的列表，忽略空白项
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// load 读取密钥文件并校验配置
+func (cfg *Config) load() error {
+	var err error
+	if cfg.PrivateKey, err = readKeyFile(cfg.PrivateKey, cfg.PrivateKeyFile); err != nil {
+		return &ConfigError{Field: "private_key_file", Err: err}
+	}
+	if cfg.IcbcPublicKey, err = readKeyFile(cfg.IcbcPublicKey, cfg.IcbcPublicKeyFile); err != nil {
+		return &ConfigError{Field: "icbc_public_key_file", Err: err}
+	}
+	return cfg.Validate()
+}
+
+// readKeyFile 返回直接配置的密钥，未配置时从文件读取
+func readKeyFile(value, file string) (string, error) {
+	if value != "" || file == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Validate 校验配置，未配置的签名类型使用默认值
+//
+// 返回值:
+//   - error: 第一个不合法字段的 *ConfigError
+func (cfg *Config) Validate() error {
+	if cfg.AppID == "" {
+		return &ConfigError{Field: "app_id", Err: errors.New("is required")}
+	}
+	if cfg.SignType == "" {
+		cfg.SignType = DefaultSignType
+	}
+	if cfg.SignType != SignTypeRSA2 && cfg.SignType != SignTypeRSA {
+		return &ConfigError{Field: "sign_type", Err: fmt.Errorf("unsupported sign type %q, expected %s or %s", cfg.SignType, SignTypeRSA2, SignTypeRSA)}
+	}
+	if cfg.PrivateKey == "" {
+		return &ConfigError{Field: "private_key", Err: errors.New("private_key or private_key_file is required")}
+	}
+	if _, err := parseRSAPrivateKey(cfg.PrivateKey); err != nil {
+		return &ConfigError{Field: "private_key", Err: err}
+	}
+	if cfg.IcbcPublicKey == "" {
+		return &ConfigError{Field: "icbc_public_key", Err: errors.New("icbc_public_key or icbc_public_key_file is required")}
+	}
+	if _, err := parseRSAPublicKey(cfg.IcbcPublicKey); err != nil {
+		return &ConfigError{Field: "icbc_public_key", Err: err}
+	}
+	if err := validateCharset(cfg.Charset); err != nil {
+		return &ConfigError{Field: "charset", Err: err}
+	}
+	if cfg.Environment != "" {
+		if _, err := Environment(cfg.Environment).BaseURL(); err != nil {
+			return &ConfigError{Field: "environment", Err: err}
+		}
+	}
+	if cfg.BaseURL != "" {
+		if err := validateURL(cfg.BaseURL, "http", "https"); err != nil {
+			return &ConfigError{Field: "base_url", Err: err}
+		}
+	}
+	if cfg.Proxy != "" {
+		if err := validateURL(cfg.Proxy, "http", "https", "socks5"); err != nil {
+			return &ConfigError{Field: "proxy", Err: err}
+		}
+	}
+	if _, err := parseDuration("timeout", cfg.Timeout, DefaultTimeout); err != nil {
+		return err
+	}
+	if _, err := parseDuration("connect_timeout", cfg.ConnectTimeout, DefaultConnectTimeout); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseDuration 解析时长配置，为空时返回默认值
+//
+// 参数:
+//   - field: 字段名，用于错误信息
+//   - value: 时长字符串
+//   - def: 默认值
+//
+// 返回值:
+//   - time.Duration: 时长
+//   - error: 错误信息
+func parseDuration(field, value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, &ConfigError{Field: field, Err: err}
+	}
+	if d < 0 {
+		return 0, &ConfigError{Field: field, Err: errors.New("must not be negative")}
+	}
+	return d, nil
+}
+
+// validateURL 校验地址包含主机名且协议为 schemes 之一
+func validateURL(s string, schemes ...string) error {
+	u, err := URL.Parse(s)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(schemes, u.Scheme) || u.Host == "" {
+		return fmt.Errorf("%q is not a valid url, expected scheme %s", s, strings.Join(schemes, ", "))
+	}
+	return nil
+}
+
+// NewClient 按配置创建客户端
+//
+// 返回值:
+//   - *DefaultClient: 客户端，HTTPClient 按配置的超时和代理创建
+//   - error: 错误信息
+func (cfg *Config) NewClient() (*DefaultClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	timeout, err := parseDuration("timeout", cfg.Timeout, DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout, err := parseDuration("connect_timeout", cfg.ConnectTimeout, DefaultConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		proxyURL, err := URL.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, &ConfigError{Field: "proxy", Err: err}
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	return &DefaultClient{
+		APPID:         cfg.AppID,
+		PrivateKey:    cfg.PrivateKey,
+		SignType:      cfg.SignType,
+		Charset:       cfg.Charset,
+		IcbcPublicKey: cfg.IcbcPublicKey,
+		BaseURL:       cfg.BaseURL,
+		Environment:   Environment(cfg.Environment),
+		HTTPClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:               proxy,
+				DialContext:         (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext,
+				TLSHandshakeTimeout: connectTimeout,
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 20,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,78 @@
+package icbc_api_sdk_go_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+)
+
+// writeConfigDir 在临时目录中写入密钥文件和配置文件，返回配置文件路径
+func writeConfigDir(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	privateKey, publicKey := generateKeyPair(t)
+	files := map[string]string{
+		"merchant_private_key.txt": privateKey,
+		"icbc_public_key.txt":      publicKey,
+		name:                       content,
+	}
+	for file, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(data), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	return filepath.Join(dir, name)
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			file:    "icbc.json",
+			content: `{"app_id":"APP001","private_key_file":"merchant_private_key.txt","icbc_public_key_file":"icbc_public_key.txt","timeout":"5s"}`,
+		},
+		{
+			name:    "unknown key",
+			file:    "icbc.json",
+			content: `{"app_id":"APP001","private_key_file":"merchant_private_key.txt","icbc_public_key_file":"icbc_public_key.txt","time_out":"5s"}`,
+			wantErr: `unknown field "time_out"`,
+		},
+		{
+			name:    "trailing data",
+			file:    "icbc.json",
+			content: `{"app_id":"APP001"} {"app_id":"APP002"}`,
+			wantErr: "unexpected data after config object",
+		},
+		{
+			name:    "yaml needs icbcconfig",
+			file:    "icbc.yaml",
+			content: "app_id: APP001\n",
+			wantErr: "icbcconfig",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := icbc.LoadConfig(writeConfigDir(t, tt.file, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadConfig error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if cfg.AppID != "APP001" || cfg.PrivateKey == "" || cfg.IcbcPublicKey == "" || cfg.SignType != icbc.DefaultSignType {
+				t.Errorf("config = %+v", cfg)
+			}
+		})
+	}
+}
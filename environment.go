@@ -0,0 +1,87 @@
+package icbc_api_sdk_go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Environment 工行网关环境
+type Environment string
+
+const (
+	EnvironmentProduction Environment = "production" // 生产环境
+	EnvironmentSandbox    Environment = "sandbox"    // 测试环境
+)
+
+// 网关地址
+const (
+	ProductionBaseURL = "https://gw.open.icbc.com.cn"   // 生产环境网关
+	SandboxBaseURL    = "https://apipcs3.dccnet.com.cn" // 测试环境网关
+)
+
+// 接口路径
+const (
+	PathShowPayUI   = "/ui/cardbusiness/aggregatepay/b2c/online/consumepurchaseshowpay/V1" // 线上POS聚合支付非埋名消费下单
+	PathOrderQuery  = "/api/cardbusiness/aggregatepay/b2c/online/orderqry/V1"              // 聚合支付B2C线上消费查询
+	PathRefund      = "/api/cardbusiness/aggregatepay/b2c/online/merrefund/V1"             // 线上POS退款
+	PathRefundQuery = "/api/cardbusiness/aggregatepay/b2c/online/refundqry/V1"             // 线上POS退款查询
+)
+
+// BaseURL 返回环境对应的网关地址
+//
+// 返回值:
+//   - string: 网关地址
+//   - error: 未知环境时返回错误
+func (e Environment) BaseURL() (string, error) {
+	switch e {
+	case EnvironmentProduction:
+		return ProductionBaseURL, nil
+	case EnvironmentSandbox:
+		return SandboxBaseURL, nil
+	default:
+		return "", fmt.Errorf("unknown environment %q, expected %q or %q", e, EnvironmentProduction, EnvironmentSandbox)
+	}
+}
+
+// ServiceURL 将接口路径拼接为完整的接口地址
+//
+// 网关地址优先使用 BaseURL，未配置时使用 Environment 对应的地址。path 已是完整地址时原样返回。
+//
+// 参数:
+//   - path: 接口路径，如 PathOrderQuery
+//
+// 返回值:
+//   - string: 完整的接口地址
+//   - error: 未配置网关地址时返回错误
+func (c *DefaultClient) ServiceURL(path string) (string, error) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path, nil
+	}
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		if c.Environment == "" {
+			return "", fmt.Errorf("base url is not configured, set BaseURL or Environment")
+		}
+		var err error
+		if baseURL, err = c.Environment.BaseURL(); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(path, "/"), nil
+}
+
+// serviceURLOrDefault 返回调用方指定的接口地址，未指定时使用默认接口路径拼接
+//
+// 参数:
+//   - serviceUrl: 调用方指定的接口地址，可为空
+//   - path: 默认接口路径
+//
+// 返回值:
+//   - string: 接口地址
+//   - error: 错误信息
+func (c *DefaultClient) serviceURLOrDefault(serviceUrl, path string) (string, error) {
+	if serviceUrl != "" {
+		return serviceUrl, nil
+	}
+	return c.ServiceURL(path)
+}
@@ -0,0 +1,28 @@
+package icbc_api_sdk_go
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSignatureVerification 响应验签失败
+var ErrSignatureVerification = errors.New("signature verification failed")
+
+// ErrURLTooLong 生成的跳转URL超过长度限制
+var ErrURLTooLong = errors.New("redirect url too long")
+
+// ErrResponseTooLarge 响应体超过 MaxResponseSize
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// ErrUnknownApp 注册表中没有对应的应用或商户
+var ErrUnknownApp = errors.New("unknown app")
+
+// StatusError 网关返回非 200 的HTTP状态码
+type StatusError struct {
+	StatusCode int    // HTTP状态码
+	Status     string // HTTP状态描述
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, response: %s", e.StatusCode, e.Status)
+}
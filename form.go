@@ -0,0 +1,265 @@
+package icbc_api_sdk_go
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"maps"
+	"net/http"
+	URL "net/url"
+	"slices"
+	"strings"
+)
+
+// FormOptions POST表单的渲染选项
+type FormOptions struct {
+	// Nonce 内容安全策略(CSP)的 nonce，非空时自动提交脚本带上 nonce 属性，
+	// 页面可使用 script-src 'nonce-...' 而无需允许 'unsafe-inline'
+	Nonce string
+	// NoScript 为 true 时不输出任何脚本，改为显示提交按钮由用户点击提交，
+	// 适用于禁止所有内联脚本的页面
+	NoScript bool
+	// Template 自定义模板，数据为 FormData，为 nil 时使用只包含表单的默认模板
+	Template *template.Template
+	// Text 页面文案，为 nil 时使用 DefaultFormText
+	Text *FormText
+	// Data 传递给自定义模板的附加数据，如品牌名称、Logo 地址
+	Data any
+	// Charset 表单的 accept-charset，非空时浏览器按该字符集提交表单，
+	// 使用 GBK 签名时必须设置为 GBK，UiIcbcClient 会按客户端的 Charset 自动设置
+	Charset string
+	// CSP PageHandler 内容安全策略的附加或覆盖指令，键为指令名，如 {"img-src": "'self' https://cdn.example.com"}，
+	// script-src 和 style-src 始终带上本次请求的 nonce
+	CSP map[string]string
+}
+
+// FormText 表单页面文案，可按语言替换
+type FormText struct {
+	Title    string // 页面标题
+	Loading  string // 跳转提示
+	Submit   string // 提交按钮文字
+	NoScript string // 浏览器禁用脚本时的提示
+}
+
+// DefaultFormText 默认的中文文案
+var DefaultFormText = FormText{
+	Title:    "正在跳转",
+	Loading:  "正在跳转到工商银行支付页面，请稍候…",
+	Submit:   "立刻提交",
+	NoScript: "您的浏览器未启用脚本，请点击按钮继续。",
+}
+
+// FormField 表单隐藏域
+type FormField struct {
+	Name  string
+	Value string
+}
+
+// FormData 表单模板的数据
+//
+// 自定义模板必须输出 action 为 Action、method 为 post 的表单，并为 Fields 中的每一项输出隐藏域，
+// 否则工行无法验签。
+type FormData struct {
+	Action   string      // 表单提交地址，包含签名等公共参数
+	Fields   []FormField // 表单隐藏域，包含 biz_content 等业务参数
+	Nonce    string      // CSP nonce，可能为空
+	NoScript bool        // 是否不输出脚本
+	Text     FormText    // 页面文案
+	Data     any         // FormOptions.Data
+	Charset  string      // 表单的 accept-charset，可能为空
+}
+
+// formTemplate POST表单模板，action、name 和 value 均由 html/template 按上下文转义
+var formTemplate = template.Must(template.New("form").Parse(
+	`<form name="auto_submit_form" method="post" action="{{.Action}}"{{if .Charset}} accept-charset="{{.Charset}}"{{end}}>` +
+		`{{range .Fields}}<input type="hidden" name="{{.Name}}" value="{{.Value}}" >
+{{end}}` +
+		`{{if .NoScript}}<input type="submit" value="{{.Text.Submit}}" >
+{{else}}<input type="submit" value="{{.Text.Submit}}" style="display:none" >
+{{end}}` +
+		"</form>\n" +
+		`{{if not .NoScript}}<script{{if .Nonce}} nonce="{{.Nonce}}"{{end}}>document.forms["auto_submit_form"].submit();</script>{{end}}`))
+
+// PageTemplate 完整的跳转页面模板，包含加载动画和禁用脚本时的提交按钮，PageHandler 默认使用该模板
+var PageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Text.Title}}</title>
+<style{{if .Nonce}} nonce="{{.Nonce}}"{{end}}>
+body{margin:0;font-family:sans-serif;color:#333;text-align:center}
+.loading{margin-top:30vh}
+.spinner{width:36px;height:36px;margin:0 auto 16px;border:4px solid #eee;border-top-color:#c7000b;border-radius:50%;animation:spin 1s linear infinite}
+@keyframes spin{to{transform:rotate(360deg)}}
+</style>
+</head>
+<body>
+<div class="loading">
+{{if not .NoScript}}<div class="spinner"></div>
+<p>{{.Text.Loading}}</p>
+{{end}}<form name="auto_submit_form" method="post" action="{{.Action}}"{{if .Charset}} accept-charset="{{.Charset}}"{{end}}>
+{{range .Fields}}<input type="hidden" name="{{.Name}}" value="{{.Value}}">
+{{end}}{{if .NoScript}}<p>{{.Text.NoScript}}</p>
+<button type="submit">{{.Text.Submit}}</button>
+{{else}}<noscript><p>{{.Text.NoScript}}</p><button type="submit">{{.Text.Submit}}</button></noscript>
+{{end}}</form>
+</div>
+{{if not .NoScript}}<script{{if .Nonce}} nonce="{{.Nonce}}"{{end}}>document.forms["auto_submit_form"].submit();</script>
+{{end}}</body>
+</html>
+`))
+
+// BuildFormWithOptions 使用 html/template 渲染POST表单页面
+//
+// 参数:
+//   - baseUrl: 表单提交的目标URL
+//   - params: 表单参数，按参数名的字典序输出，值为空的参数不输出
+//   - opts: 渲染选项，可为 nil
+//
+// 返回值:
+//   - string: 构建好的HTML表单，参数相同时输出完全相同
+//   - error: 错误信息
+func BuildFormWithOptions(baseUrl string, params *IcbcMap, opts *FormOptions) (string, error) {
+	if opts == nil {
+		opts = &FormOptions{}
+	}
+	tmpl := opts.Template
+	if tmpl == nil {
+		tmpl = formTemplate
+	}
+	text := DefaultFormText
+	if opts.Text != nil {
+		text = *opts.Text
+	}
+	var fields []FormField
+	if params != nil {
+		for key, value := range params.All() {
+			if value != "" {
+				fields = append(fields, FormField{Name: key, Value: value})
+			}
+		}
+	}
+
+	var sb strings.Builder
+	err := tmpl.Execute(&sb, &FormData{
+		Action:   baseUrl,
+		Fields:   fields,
+		Nonce:    opts.Nonce,
+		NoScript: opts.NoScript,
+		Text:     text,
+		Data:     opts.Data,
+		Charset:  opts.Charset,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render form: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// PageHandler 返回直接输出跳转页面的 http.Handler
+//
+// 每次请求调用 build 构造工行请求，签名后渲染完整的跳转页面。页面带有随机 nonce 的内容安全策略，
+// 只允许带 nonce 的脚本和样式、同源或 data: 的图片和字体，表单只能提交到工行网关；
+// 自定义模板引用其他来源的 Logo 或字体时通过 FormOptions.CSP 放开。
+// opts 为 nil 或未设置 Template 时使用 PageTemplate；opts.Nonce 会被每次生成的 nonce 覆盖。
+//
+// 参数:
+//   - build: 根据商户页面请求构造工行请求，返回错误时响应 400
+//   - opts: 渲染选项，可为 nil，为 nil 时使用客户端的 FormOptions
+//
+// 返回值:
+//   - http.Handler: 页面处理器
+func (c *UiIcbcClient) PageHandler(build func(r *http.Request) (*ICBCRequest, error), opts *FormOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := build(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		nonce, err := newNonce()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		pageOpts := FormOptions{}
+		if opts != nil {
+			pageOpts = *opts
+		} else if c.FormOptions != nil {
+			pageOpts = *c.FormOptions
+		}
+		pageOpts.Nonce = nonce
+		if pageOpts.Template == nil {
+			pageOpts.Template = PageTemplate
+		}
+
+		page, err := c.BuildPostFormWithOptions(request, &pageOpts)
+		if err != nil {
+			c.logger().Error("failed to build icbc page", "error", err)
+			http.Error(w, "failed to build page", http.StatusInternalServerError)
+			return
+		}
+		formAction := "'self'"
+		if u, err := URL.Parse(request.ServiceUrl); err == nil && u.Host != "" {
+			formAction = u.Scheme + "://" + u.Host
+		}
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Security-Policy", buildCSP(nonce, formAction, pageOpts.CSP))
+		_, _ = w.Write([]byte(page))
+	})
+}
+
+// buildCSP 构建跳转页面的内容安全策略
+//
+// 参数:
+//   - nonce: 本次请求的 nonce
+//   - formAction: 允许提交表单的地址
+//   - extra: 附加或覆盖的指令
+//
+// 返回值:
+//   - string: Content-Security-Policy 头的值，默认指令在前，附加指令按名称排序
+func buildCSP(nonce, formAction string, extra map[string]string) string {
+	directives := [][2]string{
+		{"default-src", "'none'"},
+		{"script-src", ""},
+		{"style-src", ""},
+		{"img-src", "'self' data:"},
+		{"font-src", "'self' data:"},
+		{"form-action", formAction},
+	}
+	seen := make(map[string]bool, len(directives))
+	for i := range directives {
+		name := directives[i][0]
+		seen[name] = true
+		if value, ok := extra[name]; ok {
+			directives[i][1] = value
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(extra)) {
+		if !seen[name] {
+			directives = append(directives, [2]string{name, extra[name]})
+		}
+	}
+
+	parts := make([]string, 0, len(directives))
+	for _, d := range directives {
+		value := d[1]
+		if d[0] == "script-src" || d[0] == "style-src" {
+			value = strings.TrimSpace("'nonce-" + nonce + "' " + value)
+		}
+		parts = append(parts, d[0]+" "+value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// newNonce 生成随机的 CSP nonce
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// URL 安全的 Base64 不含 + 和 /，在HTML属性中无需转义
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
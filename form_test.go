@@ -0,0 +1,303 @@
+package icbc_api_sdk_go_test
+
+import (
+	"context"
+	"flag"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // FormatTime 使用 Asia/Shanghai 时区，保证没有时区数据库的环境也能生成相同的时间戳
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// hostileValue 包含HTML特殊字符和脚本结束标签的恶意内容
+const hostileValue = `</script><script>alert('x')</script> & "quoted" <b>`
+
+// newTestUiClient 创建使用测试密钥、固定 msg_id 和时间戳的页面类客户端
+func newTestUiClient(t *testing.T) *icbc.UiIcbcClient {
+	t.Helper()
+	privateKey, err := os.ReadFile("testdata/merchant_private_key.txt")
+	if err != nil {
+		t.Fatalf("failed to read test private key: %v", err)
+	}
+	return &icbc.UiIcbcClient{DefaultClient: icbc.DefaultClient{
+		APPID:      "10000000000000000001",
+		PrivateKey: strings.TrimSpace(string(privateKey)),
+		SignType:   icbc.SignTypeRSA2,
+		BaseURL:    "https://gw.example.com",
+		MsgIDGenerator: icbc.MsgIDGeneratorFunc(func(ctx context.Context) (string, error) {
+			return "msg0001", nil
+		}),
+		Clock: icbc.FixedClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+	}}
+}
+
+// fieldValue 返回表单中指定隐藏域的 value 属性原文
+func fieldValue(t *testing.T, form, name string) string {
+	t.Helper()
+	re := regexp.MustCompile(`name="` + regexp.QuoteMeta(name) + `" value="([^"]*)"`)
+	m := re.FindStringSubmatch(form)
+	if m == nil {
+		t.Fatalf("field %s not found in form:\n%s", name, form)
+	}
+	return m[1]
+}
+
+func TestBuildPostFormEscapesHostileBizContent(t *testing.T) {
+	c := newTestUiClient(t)
+	request := &icbc.ICBCRequest{
+		ServiceUrl: "https://gw.example.com" + icbc.PathShowPayUI,
+		BizContent: map[string]string{"mer_id": "020001", "attach": hostileValue},
+	}
+
+	for _, opts := range []*icbc.FormOptions{nil, {NoScript: true}, {Template: icbc.PageTemplate}} {
+		form, err := c.BuildPostFormWithOptions(request, opts)
+		if err != nil {
+			t.Fatalf("BuildPostFormWithOptions: %v", err)
+		}
+		if strings.Contains(form, hostileValue) || strings.Contains(form, "alert('x')") {
+			t.Errorf("hostile biz_content rendered unescaped:\n%s", form)
+		}
+		wantScripts := 1
+		if opts != nil && opts.NoScript {
+			wantScripts = 0
+		}
+		if n := strings.Count(form, "<script"); n != wantScripts {
+			t.Errorf("form has %d <script> tags, want %d:\n%s", n, wantScripts, form)
+		}
+
+		// 浏览器还原后的值必须与签名的 biz_content 完全一致
+		bizContent := html.UnescapeString(fieldValue(t, form, "biz_content"))
+		want, err := c.BuildBizContentStr(request)
+		if err != nil {
+			t.Fatalf("BuildBizContentStr: %v", err)
+		}
+		if bizContent != want {
+			t.Errorf("biz_content = %q, want %q", bizContent, want)
+		}
+	}
+}
+
+// formFields 返回表单中所有隐藏域还原后的参数名和值
+func formFields(form string) map[string]string {
+	fields := make(map[string]string)
+	re := regexp.MustCompile(`<input type="hidden" name="([^"]*)" value="([^"]*)"`)
+	for _, m := range re.FindAllStringSubmatch(form, -1) {
+		fields[html.UnescapeString(m[1])] = html.UnescapeString(m[2])
+	}
+	return fields
+}
+
+// formTemplates 需要覆盖的表单渲染方式
+var formTemplates = []struct {
+	name        string
+	opts        *icbc.FormOptions
+	wantScripts int
+}{
+	{"form", nil, 1},
+	{"noscript", &icbc.FormOptions{NoScript: true}, 0},
+	{"page", &icbc.FormOptions{Template: icbc.PageTemplate, Nonce: "test-nonce"}, 1},
+}
+
+func TestBuildPostFormEscapesHostileExtraParams(t *testing.T) {
+	c := newTestUiClient(t)
+	request := &icbc.ICBCRequest{
+		ServiceUrl:  "https://gw.example.com" + icbc.PathShowPayUI,
+		BizContent:  map[string]string{"mer_id": "020001"},
+		ExtraParams: map[string]string{"hostile": hostileValue},
+	}
+	for _, tt := range formTemplates {
+		t.Run(tt.name, func(t *testing.T) {
+			form, err := c.BuildPostFormWithOptions(request, tt.opts)
+			if err != nil {
+				t.Fatalf("BuildPostFormWithOptions: %v", err)
+			}
+			if strings.Contains(form, "alert('x')") {
+				t.Errorf("hostile extra param rendered unescaped:\n%s", form)
+			}
+			if n := strings.Count(form, "<script"); n != tt.wantScripts {
+				t.Errorf("form has %d <script> tags, want %d:\n%s", n, tt.wantScripts, form)
+			}
+			// 浏览器提交的值必须与签名时的原值一致
+			if got := formFields(form)["hostile"]; got != hostileValue {
+				t.Errorf("hostile field = %q, want %q", got, hostileValue)
+			}
+		})
+	}
+}
+
+func TestBuildFormWithOptionsEscapesHostileParams(t *testing.T) {
+	hostileName := `x"><script>alert('x')</script>`
+	hostileQuery := `"><script>alert('x')</script>`
+	action := "https://gw.example.com/pay?q=" + hostileQuery
+	params := icbc.NewIcbcMap()
+	params.Put("attach", hostileValue)
+	params.Put(hostileName, "v")
+
+	for _, tt := range formTemplates {
+		t.Run(tt.name, func(t *testing.T) {
+			form, err := icbc.BuildFormWithOptions(action, params, tt.opts)
+			if err != nil {
+				t.Fatalf("BuildFormWithOptions: %v", err)
+			}
+			if strings.Contains(form, "alert('x')") {
+				t.Errorf("hostile param or action rendered unescaped:\n%s", form)
+			}
+			if n := strings.Count(form, "<script"); n != tt.wantScripts {
+				t.Errorf("form has %d <script> tags, want %d:\n%s", n, tt.wantScripts, form)
+			}
+
+			// 属性上下文：参数名和值还原后与原文一致
+			fields := formFields(form)
+			if fields["attach"] != hostileValue || fields[hostileName] != "v" || len(fields) != 2 {
+				t.Errorf("fields = %q, want attach and the hostile name restored", fields)
+			}
+
+			// URL 上下文：action 被百分号编码，解码后查询参数与原文一致
+			m := regexp.MustCompile(`<form[^>]* action="([^"]*)"`).FindStringSubmatch(form)
+			if m == nil {
+				t.Fatalf("form has no action:\n%s", form)
+			}
+			u, err := url.Parse(html.UnescapeString(m[1]))
+			if err != nil {
+				t.Fatalf("rendered action %q is not a url: %v", m[1], err)
+			}
+			if u.Host != "gw.example.com" || u.Path != "/pay" || u.Query().Get("q") != hostileQuery {
+				t.Errorf("rendered action = %q, want %q", u, action)
+			}
+		})
+	}
+}
+
+func TestBuildFormWithOptionsRejectsScriptAction(t *testing.T) {
+	form, err := icbc.BuildFormWithOptions("javascript:alert(1)", icbc.NewIcbcMap(), nil)
+	if err != nil {
+		t.Fatalf("BuildFormWithOptions: %v", err)
+	}
+	if strings.Contains(form, "javascript:") {
+		t.Errorf("javascript: action rendered:\n%s", form)
+	}
+}
+
+func TestBuildHiddenFieldsWithKVEscapes(t *testing.T) {
+	tests := []struct {
+		key, value, want string
+	}{
+		{"biz_content", `{"a":"b"}`, `<input type="hidden" name="biz_content" value="{&#34;a&#34;:&#34;b&#34;}" >` + "\n"},
+		{`a"><x`, `<'&">`, `<input type="hidden" name="a&#34;&gt;&lt;x" value="&lt;&#39;&amp;&#34;&gt;" >` + "\n"},
+		{"attach", "</script>", `<input type="hidden" name="attach" value="&lt;/script&gt;" >` + "\n"},
+	}
+	for _, tt := range tests {
+		if got := icbc.BuildHiddenFieldsWithKV(tt.key, tt.value); got != tt.want {
+			t.Errorf("BuildHiddenFieldsWithKV(%q, %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPageHandlerCSP(t *testing.T) {
+	c := newTestUiClient(t)
+	build := func(r *http.Request) (*icbc.ICBCRequest, error) {
+		return &icbc.ICBCRequest{
+			ServiceUrl: "https://gw.example.com" + icbc.PathShowPayUI,
+			BizContent: map[string]string{"mer_id": "020001"},
+		}, nil
+	}
+	tests := []struct {
+		name string
+		csp  map[string]string
+		want []string
+	}{
+		{"default", nil, []string{
+			"default-src 'none'",
+			"img-src 'self' data:",
+			"font-src 'self' data:",
+			"form-action https://gw.example.com",
+		}},
+		{"extra", map[string]string{"img-src": "https://cdn.example.com", "script-src": "https://cdn.example.com"}, []string{
+			"img-src https://cdn.example.com;",
+			"font-src 'self' data:",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c.PageHandler(build, &icbc.FormOptions{CSP: tt.csp}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pay", nil))
+			csp := rec.Header().Get("Content-Security-Policy")
+			nonce := regexp.MustCompile(`<script nonce="([^"]+)"`).FindStringSubmatch(rec.Body.String())
+			if nonce == nil {
+				t.Fatalf("page has no script nonce:\n%s", rec.Body.String())
+			}
+			want := append(tt.want, "script-src 'nonce-"+nonce[1]+"'", "style-src 'nonce-"+nonce[1]+"'")
+			for _, directive := range want {
+				if !strings.Contains(csp, directive) {
+					t.Errorf("Content-Security-Policy %q does not contain %q", csp, directive)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPostFormGolden(t *testing.T) {
+	c := newTestUiClient(t)
+	request := &icbc.ICBCRequest{
+		ServiceUrl: "https://gw.example.com" + icbc.PathShowPayUI,
+		BizContent: map[string]string{
+			"mer_id":       "020001",
+			"out_trade_no": "T20240102030405",
+			"order_amt":    "100",
+			"notify_url":   "https://merchant.example.com/notify",
+			"attach":       hostileValue,
+		},
+		ExtraParams: map[string]string{"z_extra": "1", "a_extra": "2", "m_extra": "3"},
+	}
+	tests := []struct {
+		name string
+		opts *icbc.FormOptions
+	}{
+		{"post_form", nil},
+		{"post_form_page", &icbc.FormOptions{Template: icbc.PageTemplate, Nonce: "test-nonce"}},
+		{"post_form_noscript", &icbc.FormOptions{NoScript: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.BuildPostFormWithOptions(request, tt.opts)
+			if err != nil {
+				t.Fatalf("BuildPostFormWithOptions: %v", err)
+			}
+			// 多次生成的表单必须完全一致
+			for i := 0; i < 10; i++ {
+				again, err := c.BuildPostFormWithOptions(request, tt.opts)
+				if err != nil {
+					t.Fatalf("BuildPostFormWithOptions: %v", err)
+				}
+				if again != got {
+					t.Fatalf("form output is not deterministic:\n%s\n---\n%s", got, again)
+				}
+			}
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file, run go test -update: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("form does not match %s, run go test -update if the change is intended:\n%s", golden, got)
+			}
+		})
+	}
+}
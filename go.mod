@@ -4,6 +4,5 @@ go 1.25
 
 require (
 	github.com/google/uuid v1.6.0
-	github.com/igrmk/treemap/v2 v2.0.1
-	golang.org/x/exp v0.0.0-20251125195548-87e1e737ad39
+	golang.org/x/text v0.30.0
 )
@@ -0,0 +1,214 @@
+// Package icbccassette 提供工行接口流量的录制与回放。
+//
+// Recorder 作为 DefaultClient.HTTPClient 的 Transport 录制请求参数与原始响应，保存为脱敏后的 cassette 文件；
+// Replayer 在测试中按接口路径和 biz_content 匹配回放录制的响应，匹配时忽略 msg_id、timestamp 和 sign。
+// GBK 请求和响应按请求的 charset 参数解码后以 UTF-8 保存，回放时再编码为 GBK。
+// 由于响应中的敏感字段已被脱敏，Replayer 会用自己生成的密钥重新签名响应，测试客户端需使用 Replayer.PublicKey 验签。
+package icbccassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Interaction 一次录制的请求与响应
+type Interaction struct {
+	Path        string            `json:"path"`                   // 接口路径
+	Method      string            `json:"method"`                 // HTTP 方法
+	Params      map[string]string `json:"params"`                 // 脱敏后的 UTF-8 请求参数
+	BizContent  string            `json:"biz_content"`            // 脱敏并规范化后的 biz_content，用于匹配
+	Charset     string            `json:"charset,omitempty"`      // 请求的 charset 参数，为空时为 UTF-8
+	Status      int               `json:"status"`                 // HTTP状态码
+	ContentType string            `json:"content_type,omitempty"` // 响应的 Content-Type
+	Response    string            `json:"response"`               // 脱敏后的 UTF-8 响应体
+}
+
+// Cassette 录制文件内容
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Load 读取 cassette 文件
+//
+// 参数:
+//   - path: 文件路径
+//
+// 返回值:
+//   - *Cassette: 录制内容
+//   - error: 错误信息
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Save 保存 cassette 文件
+//
+// 参数:
+//   - path: 文件路径
+//
+// 返回值:
+//   - error: 错误信息
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// readParams 读取请求的查询参数与表单参数，不修改原请求
+//
+// 请求设置了 GetBody 时通过 GetBody 读取请求体副本，原请求可直接发送；
+// 否则读取并关闭原请求体，返回携带相同请求体的请求副本用于发送。
+// 返回错误时原请求体已关闭，调用方可以直接从 RoundTrip 返回。
+//
+// 参数:
+//   - req: HTTP请求
+//
+// 返回值:
+//   - url.Values: 参数
+//   - *http.Request: 后续发送的请求
+//   - error: 错误信息
+func readParams(req *http.Request) (url.Values, *http.Request, error) {
+	params := req.URL.Query()
+	if req.Body == nil || req.Body == http.NoBody {
+		return params, req, nil
+	}
+	out := req
+	var body []byte
+	if req.GetBody != nil {
+		bodyReader, err := req.GetBody()
+		if err != nil {
+			_ = req.Body.Close()
+			return nil, nil, fmt.Errorf("failed to get request body: %w", err)
+		}
+		body, err = io.ReadAll(bodyReader)
+		_ = bodyReader.Close()
+		if err != nil {
+			_ = req.Body.Close()
+			return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	} else {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		out = req.Clone(req.Context())
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		if out == req {
+			_ = req.Body.Close()
+		}
+		return nil, nil, fmt.Errorf("failed to parse request form: %w", err)
+	}
+	for k, vs := range form {
+		for _, v := range vs {
+			params.Add(k, v)
+		}
+	}
+	return params, out, nil
+}
+
+// newInteraction 解码并脱敏请求参数，返回只包含请求部分的录制内容
+//
+// 参数:
+//   - req: HTTP请求
+//   - params: readParams 读取的参数
+//
+// 返回值:
+//   - Interaction: 录制内容
+//   - error: 参数无法解码或 biz_content 无法解析时返回错误
+func newInteraction(req *http.Request, params url.Values) (Interaction, error) {
+	params, charset, err := decodeParams(params)
+	if err != nil {
+		return Interaction{}, err
+	}
+	redacted, bizContent, err := redactParams(params)
+	if err != nil {
+		return Interaction{}, err
+	}
+	return Interaction{
+		Path:       req.URL.Path,
+		Method:     req.Method,
+		Params:     redacted,
+		BizContent: bizContent,
+		Charset:    charset,
+	}, nil
+}
+
+// decodeParams 按请求的 charset 参数将参数值解码为 UTF-8
+//
+// 参数:
+//   - params: 请求参数
+//
+// 返回值:
+//   - url.Values: UTF-8 参数
+//   - string: 请求的字符集
+//   - error: 错误信息
+func decodeParams(params url.Values) (url.Values, string, error) {
+	charset := params.Get("charset")
+	if !isGBK(charset) {
+		return params, charset, nil
+	}
+	decoded := make(url.Values, len(params))
+	for k, vs := range params {
+		for _, v := range vs {
+			dv, err := simplifiedchinese.GBK.NewDecoder().String(v)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to decode param %s as %s: %w", k, charset, err)
+			}
+			decoded.Add(k, dv)
+		}
+	}
+	return decoded, charset, nil
+}
+
+// redactParams 将请求参数脱敏，返回脱敏后的参数和规范化的 biz_content
+//
+// 参数:
+//   - params: UTF-8 请求参数
+//
+// 返回值:
+//   - map[string]string: 脱敏后的参数
+//   - string: 脱敏并规范化后的 biz_content
+//   - error: biz_content 不是合法的 JSON 时返回错误，此时无法按 biz_content 匹配
+func redactParams(params url.Values) (map[string]string, string, error) {
+	if bizContent := params.Get("biz_content"); bizContent != "" && !json.Valid([]byte(bizContent)) {
+		return nil, "", fmt.Errorf("biz_content is not valid JSON (%d bytes)", len(bizContent))
+	}
+	m := icbc.NewIcbcMap()
+	for k := range params {
+		m.Put(k, params.Get(k))
+	}
+	redacted := icbc.RedactParams(m)
+	return redacted, redacted["biz_content"], nil
+}
+
+// isGBK 判断字符集是否为 GBK
+func isGBK(charset string) bool {
+	return strings.EqualFold(charset, icbc.CharsetGBK)
+}
@@ -0,0 +1,263 @@
+package icbccassette_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbccassette"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+const (
+	cardNo = "6222020200112233445"
+	openId = "o_merchant_user_001"
+)
+
+// roundTripFunc 用函数实现 http.RoundTripper
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// staticResponse 返回固定响应体的 Transport
+func staticResponse(contentType, body string) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			_, _ = io.Copy(io.Discard, req.Body)
+			_ = req.Body.Close()
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+}
+
+// recordAndReplay 录制一次订单查询并保存，再用回放 Transport 执行同样的查询
+func recordAndReplay(t *testing.T, client *icbc.DefaultClient, next http.RoundTripper) (recorded, replayed *icbc.OrderQueryResp, cassette *icbccassette.Cassette) {
+	t.Helper()
+	ctx := context.Background()
+	query := &icbc.OrderQueryRequest{OutTradeNo: "T1", MerId: "MER001"}
+
+	recorder := icbccassette.NewRecorder(next)
+	client.HTTPClient = &http.Client{Transport: recorder}
+	recorded, _, err := client.QueryOrder(ctx, query)
+	if err != nil {
+		t.Fatalf("recording QueryOrder: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "order_query.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	replayer, err := icbccassette.LoadReplayer(path)
+	if err != nil {
+		t.Fatalf("LoadReplayer: %v", err)
+	}
+	client.HTTPClient = &http.Client{Transport: replayer}
+	client.IcbcPublicKey = replayer.PublicKey
+	replayed, _, err = client.QueryOrder(ctx, query)
+	if err != nil {
+		t.Fatalf("replaying QueryOrder: %v", err)
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("unused interactions: %+v", unused)
+	}
+	// 每条录制内容只回放一次
+	if _, _, err := client.QueryOrder(ctx, query); err == nil {
+		t.Error("QueryOrder replayed the same interaction twice")
+	}
+	return recorded, replayed, recorder.Cassette()
+}
+
+func TestRecordReplay(t *testing.T) {
+	merchantKey, merchantPub, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	gateway, err := icbctest.NewGateway(merchantPub)
+	if err != nil {
+		t.Fatalf("NewGateway: %v", err)
+	}
+	defer gateway.Close()
+	gateway.Handle(icbctest.PathOrderQuery, func(req *icbctest.Request) (any, error) {
+		return map[string]string{
+			"return_code":  "0",
+			"return_msg":   "success",
+			"msg_id":       req.MsgID,
+			"out_trade_no": "T1",
+			"pay_status":   icbc.PayStatusSuccess,
+			"card_no":      cardNo,
+			"open_id":      openId,
+		}, nil
+	})
+	client := gateway.Client("APP001", merchantKey)
+
+	recorded, replayed, cassette := recordAndReplay(t, client, http.DefaultTransport)
+	if recorded.ResponseBizContent.CardNo != cardNo {
+		t.Errorf("recorded card_no = %q, want the live value", recorded.ResponseBizContent.CardNo)
+	}
+	if biz := replayed.ResponseBizContent; biz.CardNo != icbc.RedactedValue || biz.OpenId != icbc.RedactedValue || biz.PayStatus != icbc.PayStatusSuccess {
+		t.Errorf("replayed response = %+v, want redacted card_no and open_id", biz)
+	}
+
+	if len(cassette.Interactions) != 1 {
+		t.Fatalf("recorded %d interactions, want 1", len(cassette.Interactions))
+	}
+	interaction := cassette.Interactions[0]
+	if interaction.Path != icbctest.PathOrderQuery || interaction.Status != http.StatusOK {
+		t.Errorf("interaction = %s %d", interaction.Path, interaction.Status)
+	}
+	if interaction.Params["sign"] != icbc.RedactedValue {
+		t.Errorf("recorded request sign = %q, want redacted", interaction.Params["sign"])
+	}
+	for _, secret := range []string{cardNo, openId, `"sign":"` + recorded.Sign} {
+		if strings.Contains(interaction.Response, secret) {
+			t.Errorf("recorded response contains %q: %s", secret, interaction.Response)
+		}
+	}
+}
+
+func TestRecordReplayGBK(t *testing.T) {
+	merchantKey, _, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	gatewayKey, gatewayPub, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	bizContent, err := simplifiedchinese.GBK.NewEncoder().String(`{"return_code":"0","return_msg":"交易成功","card_no":"` + cardNo + `"}`)
+	if err != nil {
+		t.Fatalf("encode GBK: %v", err)
+	}
+	sign, err := icbc.SignWithSHA1RSA(bizContent, gatewayKey)
+	if err != nil {
+		t.Fatalf("SignWithSHA1RSA: %v", err)
+	}
+	next := staticResponse("application/json; charset=GBK", `{"response_biz_content":`+bizContent+`,"sign":"`+sign+`"}`)
+	client := &icbc.DefaultClient{
+		APPID:         "APP001",
+		PrivateKey:    merchantKey,
+		IcbcPublicKey: gatewayPub,
+		Charset:       icbc.CharsetGBK,
+		BaseURL:       "https://gw.example.com",
+	}
+
+	recorded, replayed, cassette := recordAndReplay(t, client, next)
+	if recorded.ResponseBizContent.ReturnMsg != "交易成功" {
+		t.Errorf("recorded return_msg = %q", recorded.ResponseBizContent.ReturnMsg)
+	}
+	if biz := replayed.ResponseBizContent; biz.ReturnMsg != "交易成功" || biz.CardNo != icbc.RedactedValue {
+		t.Errorf("replayed response = %+v, want GBK return_msg and redacted card_no", biz)
+	}
+	interaction := cassette.Interactions[0]
+	if interaction.Charset != icbc.CharsetGBK || !strings.Contains(interaction.Response, "交易成功") {
+		t.Errorf("interaction charset %q, response %s, want UTF-8 text", interaction.Charset, interaction.Response)
+	}
+}
+
+func TestRecorderRedactsForm(t *testing.T) {
+	recorder := icbccassette.NewRecorder(staticResponse("application/x-www-form-urlencoded",
+		"return_code=0&card_no="+cardNo+"&sign=abc&biz_content="+url.QueryEscape(`{"open_id":"`+openId+`","mer_id":"MER001"}`)))
+	req, err := http.NewRequest(http.MethodPost, "https://gw.example.com/api/test", strings.NewReader("app_id=APP001&sign=abc"))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	resp.Body.Close()
+
+	recorded, err := url.ParseQuery(recorder.Cassette().Interactions[0].Response)
+	if err != nil {
+		t.Fatalf("recorded form response: %v", err)
+	}
+	want := url.Values{
+		"return_code": {"0"},
+		"card_no":     {icbc.RedactedValue},
+		"sign":        {icbc.RedactedValue},
+		"biz_content": {`{"mer_id":"MER001","open_id":"***"}`},
+	}
+	if recorded.Encode() != want.Encode() {
+		t.Errorf("recorded response = %v, want %v", recorded, want)
+	}
+}
+
+func TestRecorderRejectsUnreplayable(t *testing.T) {
+	tests := []struct {
+		name        string
+		form        string
+		contentType string
+		response    string
+	}{
+		{"invalid json response", "app_id=APP001", "application/json", `{"response_biz_content":{"card_no":"` + cardNo + `"`},
+		{"invalid form biz_content", "app_id=APP001", "application/x-www-form-urlencoded", "biz_content=%7Bcard_no"},
+		{"invalid request biz_content", "biz_content=%7Bcard_no", "application/json", `{}`},
+		{"invalid GBK response", "charset=GBK", "application/json", "{\"return_msg\":\"\x81\"}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := icbccassette.NewRecorder(staticResponse(tt.contentType, tt.response))
+			req, err := http.NewRequest(http.MethodPost, "https://gw.example.com/api/test", strings.NewReader(tt.form))
+			if err != nil {
+				t.Fatalf("NewRequest: %v", err)
+			}
+			if _, err := recorder.RoundTrip(req); err == nil {
+				t.Error("RoundTrip succeeded, want error")
+			}
+			if n := len(recorder.Cassette().Interactions); n != 0 {
+				t.Errorf("recorded %d interactions, want 0", n)
+			}
+		})
+	}
+}
+
+// trackingBody 记录是否被关闭的请求体
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRoundTripClosesBodyOnError(t *testing.T) {
+	replayer, err := icbccassette.NewReplayer(&icbccassette.Cassette{})
+	if err != nil {
+		t.Fatalf("NewReplayer: %v", err)
+	}
+	transports := map[string]http.RoundTripper{
+		"recorder": icbccassette.NewRecorder(staticResponse("application/json", "{}")),
+		"replayer": replayer,
+	}
+	errGetBody := errors.New("body already consumed")
+	for name, transport := range transports {
+		body := &trackingBody{Reader: strings.NewReader("app_id=APP001")}
+		req, err := http.NewRequest(http.MethodPost, "https://gw.example.com/api/test", body)
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		req.GetBody = func() (io.ReadCloser, error) { return nil, errGetBody }
+		if _, err := transport.RoundTrip(req); !errors.Is(err, errGetBody) {
+			t.Errorf("%s: RoundTrip error = %v, want %v", name, err, errGetBody)
+		}
+		if !body.closed {
+			t.Errorf("%s: request body not closed after error", name)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package icbccassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Recorder 录制请求与响应的 http.RoundTripper
+type Recorder struct {
+	Next http.RoundTripper // 实际发送请求的 Transport，为 nil 时使用 http.DefaultTransport
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder 创建录制 Transport
+//
+// 参数:
+//   - next: 实际发送请求的 Transport，可为 nil
+//
+// 返回值:
+//   - *Recorder: 录制 Transport
+func NewRecorder(next http.RoundTripper) *Recorder {
+	return &Recorder{Next: next}
+}
+
+// RoundTrip 发送请求并录制脱敏后的请求参数与响应体
+//
+// 请求的 biz_content 或响应体无法解析、因而无法脱敏或回放时返回错误，不写入录制内容。
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	params, outReq, err := readParams(req)
+	if err != nil {
+		return nil, err
+	}
+	interaction, err := newInteraction(req, params)
+	if err != nil {
+		if outReq.Body != nil {
+			_ = outReq.Body.Close()
+		}
+		return nil, fmt.Errorf("cannot record %s: %w", req.URL.Path, err)
+	}
+	return r.send(outReq, interaction)
+}
+
+// send 发送请求并将脱敏后的响应补充到录制内容中
+//
+// 参数:
+//   - req: 后续发送的请求
+//   - interaction: 已填充请求部分的录制内容
+//
+// 返回值:
+//   - *http.Response: 响应，响应体已被读取并替换为副本
+//   - error: 错误信息
+func (r *Recorder) send(req *http.Request, interaction Interaction) (*http.Response, error) {
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	contentType := resp.Header.Get("Content-Type")
+	redacted, err := redactBody(body, contentType, interaction.Charset)
+	if err != nil {
+		return nil, fmt.Errorf("cannot record response of %s: %w", interaction.Path, err)
+	}
+	interaction.Status = resp.StatusCode
+	interaction.ContentType = contentType
+	interaction.Response = redacted
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Cassette 返回已录制内容的副本
+//
+// 返回值:
+//   - *Cassette: 录制内容
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Save 将已录制内容保存到文件
+//
+// 参数:
+//   - path: 文件路径
+//
+// 返回值:
+//   - error: 错误信息
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// redactBody 将响应体解码为 UTF-8 并脱敏
+//
+// JSON 响应脱敏敏感字段，表单响应按参数名脱敏，其他响应(如页面)原样保留。
+//
+// 参数:
+//   - body: 响应体
+//   - contentType: 响应的 Content-Type
+//   - charset: 请求的字符集，GBK 响应按 GBK 解码
+//
+// 返回值:
+//   - string: 脱敏后的 UTF-8 响应体
+//   - error: 响应体无法按字符集解码或无法解析时返回错误
+func redactBody(body []byte, contentType, charset string) (string, error) {
+	if isGBK(charset) {
+		decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode response as %s: %w", charset, err)
+		}
+		// 解码器会把非法字节替换为 U+FFFD，重新编码不一致时回放的响应无法还原
+		if encoded, err := simplifiedchinese.GBK.NewEncoder().Bytes(decoded); err != nil || !bytes.Equal(encoded, body) {
+			return "", fmt.Errorf("response is not valid %s", charset)
+		}
+		body = decoded
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case len(trimmed) == 0:
+		return string(body), nil
+	case trimmed[0] == '{' || trimmed[0] == '[':
+		if !json.Valid(trimmed) {
+			return "", fmt.Errorf("response is not valid JSON (%d bytes)", len(body))
+		}
+		return icbc.RedactJSON(trimmed), nil
+	case mediaType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(trimmed))
+		if err != nil {
+			return "", fmt.Errorf("failed to parse form response: %w", err)
+		}
+		redacted, _, err := redactParams(form)
+		if err != nil {
+			return "", err
+		}
+		values := make(url.Values, len(redacted))
+		for k, v := range redacted {
+			values.Set(k, v)
+		}
+		return values.Encode(), nil
+	default:
+		return string(body), nil
+	}
+}
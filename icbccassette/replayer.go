@@ -0,0 +1,210 @@
+package icbccassette
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Replayer 回放录制内容的 http.RoundTripper
+//
+// 按接口路径和 biz_content 匹配录制的请求，同一请求多次出现时按录制顺序依次回放。
+type Replayer struct {
+	PublicKey string // 回放响应的验签公钥，配置为测试客户端的 IcbcPublicKey
+
+	privateKey string
+	mu         sync.Mutex
+	cassette   *Cassette
+	used       []bool
+}
+
+// NewReplayer 创建回放 Transport，并生成用于重新签名响应的密钥
+//
+// 参数:
+//   - cassette: 录制内容
+//
+// 返回值:
+//   - *Replayer: 回放 Transport
+//   - error: 错误信息
+func NewReplayer(cassette *Cassette) (*Replayer, error) {
+	if cassette == nil {
+		return nil, fmt.Errorf("cassette cannot be nil")
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate replay key: %w", err)
+	}
+	privateDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal replay private key: %w", err)
+	}
+	publicDer, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal replay public key: %w", err)
+	}
+	return &Replayer{
+		PublicKey:  base64.StdEncoding.EncodeToString(publicDer),
+		privateKey: base64.StdEncoding.EncodeToString(privateDer),
+		cassette:   cassette,
+		used:       make([]bool, len(cassette.Interactions)),
+	}, nil
+}
+
+// LoadReplayer 读取 cassette 文件并创建回放 Transport
+//
+// 参数:
+//   - path: 文件路径
+//
+// 返回值:
+//   - *Replayer: 回放 Transport
+//   - error: 错误信息
+func LoadReplayer(path string) (*Replayer, error) {
+	cassette, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(cassette)
+}
+
+// RoundTrip 查找匹配的录制内容并返回重新签名后的响应
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	params, _, err := readParams(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	key, err := newInteraction(req, params)
+	if err != nil {
+		return nil, fmt.Errorf("cannot replay %s: %w", req.URL.Path, err)
+	}
+
+	interaction, err := r.match(req.URL.Path, key.BizContent)
+	if err != nil {
+		return nil, err
+	}
+	body, err := r.resign(interaction.Response, interaction.Charset)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := interaction.ContentType
+	if contentType == "" {
+		contentType = "application/json; charset=UTF-8"
+		if !strings.HasPrefix(strings.TrimSpace(body), "{") {
+			contentType = "text/html; charset=UTF-8"
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{contentType}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Unused 返回尚未被回放的录制内容，可用于断言测试覆盖了所有录制的调用
+//
+// 返回值:
+//   - []Interaction: 未回放的录制内容
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, used := range r.used {
+		if !used {
+			unused = append(unused, r.cassette.Interactions[i])
+		}
+	}
+	return unused
+}
+
+// match 按接口路径和 biz_content 查找第一条未回放的录制内容
+//
+// 参数:
+//   - path: 接口路径
+//   - bizContent: 脱敏并规范化后的 biz_content
+//
+// 返回值:
+//   - *Interaction: 录制内容
+//   - error: 没有匹配时返回错误
+func (r *Replayer) match(path, bizContent string) (*Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.cassette.Interactions {
+		interaction := &r.cassette.Interactions[i]
+		if r.used[i] || interaction.Path != path || interaction.BizContent != bizContent {
+			continue
+		}
+		r.used[i] = true
+		return interaction, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction for %s with biz_content %s", path, bizContent)
+}
+
+// resign 使用回放密钥重新签名响应中的 response_biz_content，并按录制时的字符集编码
+//
+// 非工行 JSON 响应只做字符集编码。GBK 响应的签名基于 GBK 编码后的 response_biz_content，与工行一致。
+//
+// 参数:
+//   - body: 录制的 UTF-8 响应体
+//   - charset: 录制时请求的字符集
+//
+// 返回值:
+//   - string: 重新签名后的响应体
+//   - error: 错误信息
+func (r *Replayer) resign(body, charset string) (string, error) {
+	var resp icbc.IcbcResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil || resp.ResponseBizContent == nil {
+		return encodeCharset(charset, body)
+	}
+	bizContent, err := encodeCharset(charset, string(resp.ResponseBizContent))
+	if err != nil {
+		return "", err
+	}
+	sign, err := icbc.SignWithSHA1RSA(bizContent, r.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign replayed response: %w", err)
+	}
+	signJson, err := json.Marshal(sign)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal sign: %w", err)
+	}
+	return `{"response_biz_content":` + bizContent + `,"sign":` + string(signJson) + `}`, nil
+}
+
+// encodeCharset 将 UTF-8 字符串编码为指定字符集
+//
+// 参数:
+//   - charset: 目标字符集
+//   - s: UTF-8 字符串
+//
+// 返回值:
+//   - string: 编码后的字节
+//   - error: 存在无法编码的字符时返回错误
+func encodeCharset(charset, s string) (string, error) {
+	if !isGBK(charset) {
+		return s, nil
+	}
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode replayed response as %s: %w", charset, err)
+	}
+	return encoded, nil
+}
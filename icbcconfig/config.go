@@ -0,0 +1,78 @@
+// Package icbcconfig 读取 YAML、JSON 和 TOML 格式的客户端配置文件。
+//
+// 该包是独立的 Go 模块，YAML 和 TOML 解析库只在使用该模块时引入，SDK 本身只支持 JSON 配置。
+// 所有格式都会拒绝 icbc.Config 中不存在的键，避免拼写错误的配置项被静默忽略。
+package icbcconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Load 读取配置文件，按扩展名识别格式(.yaml/.yml、.json、.toml)，并加载密钥文件、校验配置
+//
+// 配置文件中的相对密钥路径以配置文件所在目录为基准。
+//
+// 参数:
+//   - path: 配置文件路径
+//
+// 返回值:
+//   - *icbc.Config: 校验后的配置，密钥文件内容已读取到 PrivateKey 和 IcbcPublicKey
+//   - error: 错误信息，字段错误为 *icbc.ConfigError
+func Load(path string) (*icbc.Config, error) {
+	var decode icbc.ConfigDecoder
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decode = DecodeYAML
+	case ".json":
+		decode = icbc.DecodeJSONConfig
+	case ".toml":
+		decode = DecodeTOML
+	default:
+		return nil, fmt.Errorf("unsupported config format %q, expected .yaml, .yml, .json or .toml", ext)
+	}
+	return icbc.LoadConfigWithDecoder(path, decode)
+}
+
+// DecodeYAML 解析 YAML 配置，存在未知的键时返回包含键名和行号的错误
+//
+// 参数:
+//   - r: 配置内容
+//   - cfg: 解析结果
+//
+// 返回值:
+//   - error: 错误信息
+func DecodeYAML(r io.Reader, cfg *icbc.Config) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// DecodeTOML 解析 TOML 配置，存在未知的键时返回指明该键的 *icbc.ConfigError
+//
+// 参数:
+//   - r: 配置内容
+//   - cfg: 解析结果
+//
+// 返回值:
+//   - error: 错误信息
+func DecodeTOML(r io.Reader, cfg *icbc.Config) error {
+	meta, err := toml.NewDecoder(r).Decode(cfg)
+	if err != nil {
+		return err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return &icbc.ConfigError{Field: undecoded[0].String(), Err: errors.New("unknown key")}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package icbcconfig_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbcconfig"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+)
+
+// writeConfigDir 在临时目录中写入密钥文件和配置文件，返回配置文件路径
+func writeConfigDir(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	privateKey, publicKey, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	files := map[string]string{
+		"merchant_private_key.txt": privateKey,
+		"icbc_public_key.txt":      publicKey,
+		name:                       content,
+	}
+	for file, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(data), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	return filepath.Join(dir, name)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: "icbc.yaml",
+			content: `app_id: APP001
+mer_ids: [MER001, MER002]
+sign_type: RSA
+private_key_file: merchant_private_key.txt
+icbc_public_key_file: icbc_public_key.txt
+timeout: 5s
+`,
+		},
+		{
+			name: "yaml unknown key",
+			file: "icbc.yml",
+			content: `app_id: APP001
+private_key_file: merchant_private_key.txt
+icbc_public_key_file: icbc_public_key.txt
+time_out: 5s
+`,
+			wantErr: "field time_out not found",
+		},
+		{
+			name:    "json",
+			file:    "icbc.json",
+			content: `{"app_id":"APP001","mer_ids":["MER001","MER002"],"sign_type":"RSA","private_key_file":"merchant_private_key.txt","icbc_public_key_file":"icbc_public_key.txt","timeout":"5s"}`,
+		},
+		{
+			name:    "json unknown key",
+			file:    "icbc.json",
+			content: `{"app_id":"APP001","private_key_file":"merchant_private_key.txt","icbc_public_key_file":"icbc_public_key.txt","time_out":"5s"}`,
+			wantErr: `unknown field "time_out"`,
+		},
+		{
+			name: "toml",
+			file: "icbc.toml",
+			content: `app_id = "APP001"
+mer_ids = ["MER001", "MER002"]
+sign_type = "RSA"
+private_key_file = "merchant_private_key.txt"
+icbc_public_key_file = "icbc_public_key.txt"
+timeout = "5s"
+`,
+		},
+		{
+			name: "toml unknown key",
+			file: "icbc.toml",
+			content: `app_id = "APP001"
+private_key_file = "merchant_private_key.txt"
+icbc_public_key_file = "icbc_public_key.txt"
+time_out = "5s"
+`,
+			wantErr: "invalid config time_out: unknown key",
+		},
+		{
+			name:    "unsupported format",
+			file:    "icbc.ini",
+			content: "app_id=APP001\n",
+			wantErr: `unsupported config format ".ini"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := icbcconfig.Load(writeConfigDir(t, tt.file, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.AppID != "APP001" || cfg.SignType != icbc.SignTypeRSA || cfg.Timeout != "5s" || strings.Join(cfg.MerIDs, ",") != "MER001,MER002" {
+				t.Errorf("config = %+v", cfg)
+			}
+			if cfg.PrivateKey == "" || cfg.IcbcPublicKey == "" {
+				t.Error("key files were not loaded relative to the config file")
+			}
+		})
+	}
+}
+
+func TestDecodeTOMLUnknownKeyError(t *testing.T) {
+	var cfg icbc.Config
+	err := icbcconfig.DecodeTOML(strings.NewReader("app_id = \"APP001\"\n[proxy_settings]\nhost = \"127.0.0.1\"\n"), &cfg)
+	var configErr *icbc.ConfigError
+	if !errors.As(err, &configErr) || configErr.Field != "proxy_settings" {
+		t.Errorf("DecodeTOML error = %v, want *ConfigError for proxy_settings", err)
+	}
+}
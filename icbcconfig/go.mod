@@ -0,0 +1,17 @@
+module github.com/ljjdev/icbc-api-sdk-go/icbcconfig
+
+go 1.25
+
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/ljjdev/icbc-api-sdk-go v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/text v0.30.0 // indirect
+)
+
+// 与 SDK 在同一仓库中开发，使用本地的 SDK 源码
+replace github.com/ljjdev/icbc-api-sdk-go => ..
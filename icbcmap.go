@@ -2,6 +2,9 @@ package icbc_api_sdk_go
 
 import (
 	"fmt"
+	"iter"
+	"maps"
+	"slices"
 	"strconv"
 	"time"
 )
@@ -154,6 +157,30 @@ func (m *IcbcMap) GetBool(key string) (bool, error) {
 	return strconv.ParseBool(value)
 }
 
+// Len 返回键值对数量
+// @return int 数量
+func (m *IcbcMap) Len() int {
+	return len(m.data)
+}
+
+// Keys 返回按字典序排序的所有键
+// @return []string 键
+func (m *IcbcMap) Keys() []string {
+	return slices.Sorted(maps.Keys(m.data))
+}
+
+// All 按键的字典序遍历所有键值对，每次遍历的顺序都相同
+// @return iter.Seq2[string, string] 键值对迭代器
+func (m *IcbcMap) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, k := range m.Keys() {
+			if !yield(k, m.data[k]) {
+				return
+			}
+		}
+	}
+}
+
 // String 方法，方便打印整个 map 的内容
 func (m *IcbcMap) String() string {
 	return fmt.Sprintf("%v", m.data)
@@ -0,0 +1,231 @@
+package icbctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+)
+
+// checkoutTemplate 模拟收银台页面
+var checkoutTemplate = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>ICBC 模拟收银台</title></head>
+<body>
+<h1>ICBC 模拟收银台</h1>
+<p>商户订单号: <span id="out_trade_no">{{.Order.OutTradeNo}}</span></p>
+<p>工行订单号: <span id="order_id">{{.Order.OrderId}}</span></p>
+<p>订单金额(分): <span id="total_amt">{{.Order.TotalAmt}}</span></p>
+<p>订单状态: <span id="pay_status">{{.Order.PayStatus}}</span></p>
+{{if .Message}}<p id="message">{{.Message}}</p>{{end}}
+{{if eq .Order.PayStatus "0"}}
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="out_trade_no" value="{{.Order.OutTradeNo}}">
+<button type="submit" name="action" value="pay">确认支付</button>
+<button type="submit" name="action" value="fail">模拟支付失败</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+// servePayUI 处理下单页面请求，创建待支付订单并展示模拟收银台
+func (g *Gateway) servePayUI(w http.ResponseWriter, req *Request) {
+	var biz icbc.ShowPayUIRequest
+	if err := json.Unmarshal(req.BizContent, &biz); err != nil {
+		http.Error(w, "invalid biz_content: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if biz.OutTradeNo == "" {
+		http.Error(w, "out_trade_no is required", http.StatusBadRequest)
+		return
+	}
+	amt, err := strconv.ParseInt(biz.OrderAmt, 10, 64)
+	if err != nil || amt <= 0 {
+		http.Error(w, "invalid order_amt: "+biz.OrderAmt, http.StatusBadRequest)
+		return
+	}
+
+	g.mu.Lock()
+	order, ok := g.orders[biz.OutTradeNo]
+	if !ok {
+		order = &Order{
+			AppID:      req.AppID,
+			MerId:      biz.MerId,
+			OutTradeNo: biz.OutTradeNo,
+			OrderId:    g.nextId("ORD"),
+			TotalAmt:   amt,
+			PayStatus:  icbc.PayStatusPaying,
+			NotifyUrl:  biz.NotifyUrl,
+			ReturnUrl:  biz.ReturnUrl,
+			Attach:     biz.Attach,
+		}
+		g.orders[biz.OutTradeNo] = order
+	}
+	snapshot := *order
+	g.mu.Unlock()
+
+	g.renderCheckout(w, snapshot, "")
+}
+
+// serveCheckout 处理模拟收银台的支付或失败提交
+func (g *Gateway) serveCheckout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	outTradeNo := r.PostForm.Get("out_trade_no")
+
+	var err error
+	switch action := r.PostForm.Get("action"); action {
+	case "pay":
+		err = g.Pay(r.Context(), outTradeNo)
+	case "fail":
+		err = g.Fail(outTradeNo)
+	default:
+		http.Error(w, "unknown action: "+action, http.StatusBadRequest)
+		return
+	}
+
+	order, ok := g.Order(outTradeNo)
+	if !ok {
+		http.Error(w, "order not found", http.StatusNotFound)
+		return
+	}
+	if err == nil && order.ReturnUrl != "" {
+		http.Redirect(w, r, order.ReturnUrl, http.StatusSeeOther)
+		return
+	}
+	message := "处理完成"
+	if err != nil {
+		message = err.Error()
+	}
+	g.renderCheckout(w, order, message)
+}
+
+// renderCheckout 渲染模拟收银台页面
+func (g *Gateway) renderCheckout(w http.ResponseWriter, order Order, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	err := checkoutTemplate.Execute(w, struct {
+		Order   Order
+		Message string
+		Action  string
+	}{order, message, PathCheckout})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Fail 将待支付订单标记为支付失败
+//
+// 参数:
+//   - outTradeNo: 商户订单号
+//
+// 返回值:
+//   - error: 错误信息
+func (g *Gateway) Fail(outTradeNo string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	order, ok := g.orders[outTradeNo]
+	if !ok {
+		return fmt.Errorf("order %s not found", outTradeNo)
+	}
+	if order.PayStatus != icbc.PayStatusPaying {
+		return fmt.Errorf("order %s cannot fail in status %s", outTradeNo, order.PayStatus)
+	}
+	order.PayStatus = icbc.PayStatusFailed
+	return nil
+}
+
+// SetRefundStatus 修改退款记录的状态，用于模拟异步退款完成或失败
+//
+// 参数:
+//   - outTradeNo: 商户订单号
+//   - outtrxSerialNo: 外部退款流水号
+//   - status: 退款状态，如 icbc.RefundStatusSuccess
+//
+// 返回值:
+//   - error: 错误信息
+func (g *Gateway) SetRefundStatus(outTradeNo, outtrxSerialNo, status string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	order, ok := g.orders[outTradeNo]
+	if !ok {
+		return fmt.Errorf("order %s not found", outTradeNo)
+	}
+	for i := range order.Refunds {
+		if order.Refunds[i].OuttrxSerialNo == outtrxSerialNo {
+			order.Refunds[i].PayStatus = status
+			order.updateRefundStatus()
+			return nil
+		}
+	}
+	return fmt.Errorf("refund %s not found in order %s", outtrxSerialNo, outTradeNo)
+}
+
+// 对账单交易类型
+const (
+	EntryTypePay    = "PAY"
+	EntryTypeRefund = "REFUND"
+)
+
+// StatementEntry 对账单明细
+type StatementEntry struct {
+	Type           string // 交易类型，EntryTypePay 或 EntryTypeRefund
+	OutTradeNo     string
+	OrderId        string
+	OuttrxSerialNo string // 退款时为外部退款流水号
+	IntrxSerialNo  string // 退款时为工行退款流水号
+	Amount         int64  // 金额，单位分
+	Time           string // 支付或退款时间
+}
+
+// Statement 生成对账单，包含所有支付成功的订单和退款成功的记录，按时间排序
+//
+// 返回值:
+//   - []StatementEntry: 对账单明细
+func (g *Gateway) Statement() []StatementEntry {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var entries []StatementEntry
+	for _, order := range g.orders {
+		if order.PayTime == "" {
+			continue
+		}
+		entries = append(entries, StatementEntry{
+			Type:       EntryTypePay,
+			OutTradeNo: order.OutTradeNo,
+			OrderId:    order.OrderId,
+			Amount:     order.TotalAmt,
+			Time:       order.PayTime,
+		})
+		for _, refund := range order.Refunds {
+			if refund.PayStatus != icbc.RefundStatusSuccess {
+				continue
+			}
+			entries = append(entries, StatementEntry{
+				Type:           EntryTypeRefund,
+				OutTradeNo:     order.OutTradeNo,
+				OrderId:        order.OrderId,
+				OuttrxSerialNo: refund.OuttrxSerialNo,
+				IntrxSerialNo:  refund.IntrxSerialNo,
+				Amount:         refund.RejectAmt,
+				Time:           refund.RefundTime,
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Time != entries[j].Time {
+			return entries[i].Time < entries[j].Time
+		}
+		if entries[i].OrderId != entries[j].OrderId {
+			return entries[i].OrderId < entries[j].OrderId
+		}
+		return entries[i].IntrxSerialNo < entries[j].IntrxSerialNo
+	})
+	return entries
+}
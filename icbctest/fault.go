@@ -0,0 +1,93 @@
+package icbctest
+
+import (
+	"net/http"
+	"time"
+)
+
+// Fault 故障注入配置
+//
+// 同一个 Fault 可以组合多种故障，执行顺序为延迟、HTTP状态码、业务返回码、错误签名。
+type Fault struct {
+	Path         string        // 生效的接口路径，为空时对所有签名接口生效
+	Times        int           // 生效次数，0 表示一直生效直到 ClearFaults
+	AfterHandler bool          // 是否在处理函数执行之后注入，用于模拟"已处理但响应丢失"
+	Delay        time.Duration // 响应前的延迟，配合客户端超时模拟网关超时
+	HTTPStatus   int           // 非 0 时直接返回该HTTP状态码
+	ReturnCode   string        // 非空时返回该业务返回码，替代处理函数的结果
+	ReturnMsg    string        // 与 ReturnCode 一起返回的返回信息
+	BadSignature bool          // 是否返回错误的响应签名
+}
+
+// InjectFault 注入故障，按注入顺序匹配，每次请求最多触发一个故障
+//
+// 参数:
+//   - fault: 故障配置
+func (g *Gateway) InjectFault(fault Fault) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.faults = append(g.faults, &fault)
+}
+
+// ClearFaults 清除所有已注入的故障
+func (g *Gateway) ClearFaults() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.faults = nil
+}
+
+// takeFault 取出与接口路径匹配的故障，并扣减剩余生效次数
+//
+// 参数:
+//   - path: 接口路径
+//
+// 返回值:
+//   - *Fault: 匹配的故障，没有时为 nil
+func (g *Gateway) takeFault(path string) *Fault {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, fault := range g.faults {
+		if fault.Path != "" && fault.Path != path {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				g.faults = append(g.faults[:i:i], g.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+// applyFault 执行故障注入
+//
+// 参数:
+//   - w: 响应
+//   - r: 请求
+//   - req: 网关请求
+//   - fault: 故障配置
+//
+// 返回值:
+//   - bool: 是否已写回响应，为 true 时调用方不应再写响应
+func (g *Gateway) applyFault(w http.ResponseWriter, r *http.Request, req *Request, fault *Fault) bool {
+	if fault.Delay > 0 {
+		timer := time.NewTimer(fault.Delay)
+		defer timer.Stop()
+		select {
+		case <-r.Context().Done():
+			return true
+		case <-timer.C:
+		}
+	}
+	if fault.HTTPStatus != 0 {
+		http.Error(w, http.StatusText(fault.HTTPStatus), fault.HTTPStatus)
+		return true
+	}
+	if fault.ReturnCode != "" {
+		g.writeResponse(w, &BizError{ReturnCode: fault.ReturnCode, ReturnMsg: fault.ReturnMsg, MsgId: req.MsgID}, fault.BadSignature)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,310 @@
+// Package icbctest 提供基于 httptest 的工行网关模拟服务，用于在无法访问工行的环境中进行集成测试。
+//
+// 网关会使用商户公钥校验请求签名，按接口路径路由到脚本化或有状态的处理函数，
+// 使用自动生成的网关私钥对响应签名，并可以向 notify_url 发送异步通知。
+package icbctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+)
+
+// 模拟网关支持的接口路径
+const (
+	PathPayUI       = icbc.PathShowPayUI   // 线上POS聚合支付非埋名消费下单
+	PathOrderQuery  = icbc.PathOrderQuery  // 聚合支付B2C线上消费查询
+	PathRefund      = icbc.PathRefund      // 线上POS退款
+	PathRefundQuery = icbc.PathRefundQuery // 线上POS退款查询
+)
+
+// 模拟网关使用的业务返回码
+const (
+	ReturnCodeSignFailed    = "400017" // 请求验签失败
+	ReturnCodeOrderNotFound = "10001"  // 订单或退款记录不存在
+	ReturnCodeInvalidState  = "10002"  // 订单状态不允许该操作
+	ReturnCodeInvalidAmount = "10003"  // 金额不合法或超过可退金额
+	ReturnCodeRefunding     = "10004"  // 退款已受理但处理中，需通过退款查询确认结果
+	ReturnCodeRefundFailed  = "10005"  // 退款失败
+)
+
+// PathCheckout 模拟收银台的支付提交地址
+const PathCheckout = "/icbctest/checkout"
+
+// Request 网关收到的一次接口请求
+type Request struct {
+	Path       string          // 接口路径
+	Params     url.Values      // 查询参数与表单参数
+	AppID      string          // app_id
+	MsgID      string          // msg_id
+	BizContent json.RawMessage // biz_content 原文
+}
+
+// Handler 接口处理函数，返回值会被序列化为 response_biz_content 并由网关私钥签名
+// 返回 error 时网关响应 HTTP 500
+type Handler func(req *Request) (any, error)
+
+// BizError 业务错误响应内容
+type BizError struct {
+	ReturnCode string `json:"return_code"`
+	ReturnMsg  string `json:"return_msg"`
+	MsgId      string `json:"msg_id,omitempty"`
+}
+
+// Gateway 模拟工行网关
+type Gateway struct {
+	URL               string       // 网关地址，可作为 ServiceUrl 的前缀
+	PublicKey         string       // 网关公钥，配置为客户端的 IcbcPublicKey
+	PrivateKey        string       // 网关私钥，用于响应和异步通知签名
+	MerchantPublicKey string       // 商户公钥，用于校验请求签名
+	NotifyClient      *http.Client // 发送异步通知使用的HTTP客户端，为 nil 时使用 http.DefaultClient
+	NewRefundStatus   string       // 新退款的初始状态，为空时直接退款成功；设为处理中可配合 SetRefundStatus 模拟异步退款
+
+	server   *httptest.Server
+	mu       sync.Mutex
+	handlers map[string]Handler
+	requests []Request
+	orders   map[string]*Order
+	seq      int
+	faults   []*Fault
+}
+
+// NewGateway 创建并启动模拟网关，默认注册有状态的下单、查询、退款和退款查询处理函数
+//
+// 参数:
+//   - merchantPublicKey: Base64 编码的商户 RSA 公钥
+//
+// 返回值:
+//   - *Gateway: 模拟网关，使用完毕后需调用 Close
+//   - error: 错误信息
+func NewGateway(merchantPublicKey string) (*Gateway, error) {
+	privateKey, publicKey, err := GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate gateway key: %w", err)
+	}
+	g := &Gateway{
+		PublicKey:         publicKey,
+		PrivateKey:        privateKey,
+		MerchantPublicKey: merchantPublicKey,
+		handlers:          make(map[string]Handler),
+		orders:            make(map[string]*Order),
+	}
+	g.handlers[PathOrderQuery] = g.handleOrderQuery
+	g.handlers[PathRefund] = g.handleRefund
+	g.handlers[PathRefundQuery] = g.handleRefundQuery
+
+	g.server = httptest.NewServer(g)
+	g.URL = g.server.URL
+	return g, nil
+}
+
+// Close 关闭模拟网关
+func (g *Gateway) Close() {
+	g.server.Close()
+}
+
+// Handle 注册或替换指定接口路径的处理函数，可用于返回脚本化的响应
+//
+// 参数:
+//   - path: 接口路径
+//   - handler: 处理函数
+func (g *Gateway) Handle(path string, handler Handler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.handlers[path] = handler
+}
+
+// Requests 返回网关已收到的请求
+//
+// 返回值:
+//   - []Request: 请求列表
+func (g *Gateway) Requests() []Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Request(nil), g.requests...)
+}
+
+// ServiceURL 返回指定接口路径在模拟网关上的完整地址
+//
+// 参数:
+//   - path: 接口路径
+//
+// 返回值:
+//   - string: 完整地址
+func (g *Gateway) ServiceURL(path string) string {
+	return g.URL + path
+}
+
+// Client 创建连接到模拟网关的客户端，BaseURL 为模拟网关地址，可直接调用 QueryOrder 等接口方法
+//
+// 参数:
+//   - appID: 应用ID
+//   - merchantPrivateKey: 与 MerchantPublicKey 对应的商户私钥
+//
+// 返回值:
+//   - *icbc.DefaultClient: 客户端
+func (g *Gateway) Client(appID, merchantPrivateKey string) *icbc.DefaultClient {
+	return &icbc.DefaultClient{
+		APPID:         appID,
+		PrivateKey:    merchantPrivateKey,
+		SignType:      "RSA2",
+		IcbcPublicKey: g.PublicKey,
+		BaseURL:       g.URL,
+		HTTPClient:    g.server.Client(),
+	}
+}
+
+// ServeHTTP 校验请求签名并按接口路径分发
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	// 模拟收银台页面不需要签名
+	if r.URL.Path == PathCheckout {
+		g.serveCheckout(w, r)
+		return
+	}
+
+	req := &Request{
+		Path:       r.URL.Path,
+		Params:     r.Form,
+		AppID:      r.Form.Get("app_id"),
+		MsgID:      r.Form.Get("msg_id"),
+		BizContent: json.RawMessage(r.Form.Get("biz_content")),
+	}
+
+	g.mu.Lock()
+	g.requests = append(g.requests, *req)
+	handler := g.handlers[req.Path]
+	g.mu.Unlock()
+
+	if err := g.verifyRequest(req); err != nil {
+		if req.Path == PathPayUI {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		g.writeResponse(w, &BizError{ReturnCode: ReturnCodeSignFailed, ReturnMsg: err.Error(), MsgId: req.MsgID}, false)
+		return
+	}
+
+	if req.Path == PathPayUI && handler == nil {
+		g.servePayUI(w, req)
+		return
+	}
+	if handler == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	fault := g.takeFault(req.Path)
+	if fault != nil && !fault.AfterHandler && g.applyFault(w, r, req, fault) {
+		return
+	}
+	biz, err := handler(req)
+	if fault != nil && fault.AfterHandler && g.applyFault(w, r, req, fault) {
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	g.writeResponse(w, biz, fault != nil && fault.BadSignature)
+}
+
+// verifyRequest 使用商户公钥校验请求签名
+//
+// 参数:
+//   - req: 网关请求
+//
+// 返回值:
+//   - error: 验签失败原因
+func (g *Gateway) verifyRequest(req *Request) error {
+	sign := req.Params.Get("sign")
+	if sign == "" {
+		return fmt.Errorf("sign is missing")
+	}
+	params := icbc.NewIcbcMap()
+	for k := range req.Params {
+		if k != "sign" {
+			params.Put(k, req.Params.Get(k))
+		}
+	}
+	signStr := icbc.BuildOrderedSignStr(params, req.Path)
+	verify := icbc.VerifySHA256RSA
+	if req.Params.Get("sign_type") == icbc.SignTypeRSA {
+		verify = icbc.VerifySHA1RSA
+	}
+	pass, err := verify(signStr, sign, g.MerchantPublicKey)
+	if err != nil {
+		return fmt.Errorf("verify sign failed: %w", err)
+	}
+	if !pass {
+		return fmt.Errorf("verify sign failed")
+	}
+	return nil
+}
+
+// writeResponse 序列化业务内容并签名后写回
+//
+// 参数:
+//   - w: 响应
+//   - biz: 业务内容
+//   - badSignature: 是否写回错误的签名
+func (g *Gateway) writeResponse(w http.ResponseWriter, biz any, badSignature bool) {
+	body, err := g.buildResponse(biz, badSignature)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	_, _ = io.WriteString(w, body)
+}
+
+// SignResponse 构建网关签名后的响应体 {"response_biz_content":...,"sign":"..."}
+//
+// 参数:
+//   - biz: 业务内容
+//
+// 返回值:
+//   - string: 响应体
+//   - error: 错误信息
+func (g *Gateway) SignResponse(biz any) (string, error) {
+	return g.buildResponse(biz, false)
+}
+
+// buildResponse 构建响应体
+//
+// 参数:
+//   - biz: 业务内容
+//   - badSignature: 是否生成错误的签名
+//
+// 返回值:
+//   - string: 响应体
+//   - error: 错误信息
+func (g *Gateway) buildResponse(biz any, badSignature bool) (string, error) {
+	bizJson, err := json.Marshal(biz)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal biz content: %w", err)
+	}
+	signData := string(bizJson)
+	if badSignature {
+		// 对不同的内容签名，客户端验签必然失败
+		signData += " "
+	}
+	sign, err := icbc.SignWithSHA1RSA(signData, g.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign response: %w", err)
+	}
+	signJson, err := json.Marshal(sign)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal sign: %w", err)
+	}
+	return `{"response_biz_content":` + string(bizJson) + `,"sign":` + string(signJson) + `}`, nil
+}
@@ -0,0 +1,318 @@
+package icbctest_test
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+)
+
+// newGateway 启动模拟网关并返回连接到它的客户端
+func newGateway(t *testing.T) (*icbctest.Gateway, *icbc.DefaultClient) {
+	t.Helper()
+	merchantKey, merchantPub, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	gateway, err := icbctest.NewGateway(merchantPub)
+	if err != nil {
+		t.Fatalf("NewGateway: %v", err)
+	}
+	t.Cleanup(gateway.Close)
+	return gateway, gateway.Client("APP001", merchantKey)
+}
+
+var (
+	formActionPattern = regexp.MustCompile(`<form[^>]* action="([^"]*)"`)
+	formFieldPattern  = regexp.MustCompile(`<input type="hidden" name="([^"]*)" value="([^"]*)"`)
+)
+
+// submitForm 按浏览器的方式提交 HTML 页面中的第一个表单，不跟随跳转
+func submitForm(t *testing.T, client *http.Client, page, baseUrl string, extra url.Values) *http.Response {
+	t.Helper()
+	action := formActionPattern.FindStringSubmatch(page)
+	if action == nil {
+		t.Fatalf("no form in page:\n%s", page)
+	}
+	target, err := url.Parse(baseUrl)
+	if err != nil {
+		t.Fatalf("invalid base url: %v", err)
+	}
+	target, err = target.Parse(html.UnescapeString(action[1]))
+	if err != nil {
+		t.Fatalf("invalid form action: %v", err)
+	}
+	form := url.Values{}
+	for _, field := range formFieldPattern.FindAllStringSubmatch(page, -1) {
+		form.Set(html.UnescapeString(field[1]), html.UnescapeString(field[2]))
+	}
+	for k, v := range extra {
+		form[k] = v
+	}
+	resp, err := client.PostForm(target.String(), form)
+	if err != nil {
+		t.Fatalf("submit form to %s: %v", target, err)
+	}
+	return resp
+}
+
+func TestCheckoutPayRefundFlow(t *testing.T) {
+	gateway, client := newGateway(t)
+	ctx := context.Background()
+
+	notifies := make(chan *icbc.Notify, 1)
+	merchant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notify, err := client.ParseNotify(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		notifies <- notify
+		resp, _ := client.BuildNotifyResponse(0, "success", notify.MsgId)
+		_, _ = w.Write([]byte(resp))
+	}))
+	defer merchant.Close()
+
+	// 下单：提交 SDK 生成的表单得到模拟收银台
+	ui := &icbc.UiIcbcClient{DefaultClient: *client}
+	payForm, err := ui.BuildPayForm(&icbc.ShowPayUIRequest{
+		MerId:      "MER001",
+		OutTradeNo: "T1",
+		OrderAmt:   "1000",
+		NotifyUrl:  merchant.URL + "/notify",
+		ReturnUrl:  "https://merchant.example.com/return",
+	})
+	if err != nil {
+		t.Fatalf("BuildPayForm: %v", err)
+	}
+	browser := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp := submitForm(t, browser, payForm, gateway.URL, nil)
+	checkout := readBody(t, resp)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(checkout, `<span id="pay_status">`+icbc.PayStatusPaying+`</span>`) {
+		t.Fatalf("checkout page status %d:\n%s", resp.StatusCode, checkout)
+	}
+
+	// 支付：收银台确认后发送异步通知并跳转回商户
+	resp = submitForm(t, browser, checkout, gateway.URL, url.Values{"action": {"pay"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != "https://merchant.example.com/return" {
+		t.Errorf("checkout response %d, Location %q, want redirect to return_url", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	select {
+	case notify := <-notifies:
+		if notify.OutTradeNo != "T1" || notify.TotalAmt != "1000" || notify.ReturnCode != "0" {
+			t.Errorf("notify = %+v", notify)
+		}
+	default:
+		t.Fatal("merchant did not receive the pay notify")
+	}
+
+	order, _, err := client.QueryOrder(ctx, &icbc.OrderQueryRequest{OutTradeNo: "T1"})
+	if err != nil {
+		t.Fatalf("QueryOrder: %v", err)
+	}
+	if order.ResponseBizContent.PayStatus != icbc.PayStatusSuccess {
+		t.Fatalf("pay_status = %s, want %s", order.ResponseBizContent.PayStatus, icbc.PayStatusSuccess)
+	}
+
+	// 退款：超过剩余可退金额的退款被拒绝，全额退款后不能再退
+	refunds := []struct {
+		serialNo   string
+		amount     string
+		returnCode string
+		payStatus  string
+	}{
+		{"R1", "300", icbc.ReturnCodeSuccess, icbc.PayStatusPartRefunded},
+		{"R2", "701", icbctest.ReturnCodeInvalidAmount, icbc.PayStatusPartRefunded},
+		{"R3", "700", icbc.ReturnCodeSuccess, icbc.PayStatusFullRefunded},
+		{"R4", "1", icbctest.ReturnCodeInvalidState, icbc.PayStatusFullRefunded},
+	}
+	for _, r := range refunds {
+		refund, _, err := client.Refund(ctx, &icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: r.serialNo, RetTotalAmt: r.amount})
+		if err != nil {
+			t.Fatalf("Refund %s: %v", r.serialNo, err)
+		}
+		if got := refund.ResponseBizContent.ReturnCode; got != r.returnCode {
+			t.Errorf("refund %s return_code = %s, want %s", r.serialNo, got, r.returnCode)
+		}
+		if got, _ := gateway.Order("T1"); got.PayStatus != r.payStatus {
+			t.Errorf("after refund %s pay_status = %s, want %s", r.serialNo, got.PayStatus, r.payStatus)
+		}
+	}
+	query, _, err := client.QueryRefund(ctx, &icbc.QueryRefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R1"})
+	if err != nil {
+		t.Fatalf("QueryRefund: %v", err)
+	}
+	if biz := query.ResponseBizContent; biz.PayStatus != icbc.RefundStatusSuccess || biz.RejectAmt != "300" {
+		t.Errorf("refund query = %+v, want R1 succeeded with reject_amt 300", biz)
+	}
+
+	// 对账单：一笔支付和两笔成功的退款
+	var summary []string
+	var total int64
+	for _, entry := range gateway.Statement() {
+		summary = append(summary, entry.Type+" "+entry.OuttrxSerialNo)
+		if entry.Type == icbctest.EntryTypeRefund {
+			total -= entry.Amount
+		} else {
+			total += entry.Amount
+		}
+	}
+	if got, want := strings.Join(summary, ","), "PAY ,REFUND R1,REFUND R3"; got != want {
+		t.Errorf("statement = %s, want %s", got, want)
+	}
+	if total != 0 {
+		t.Errorf("statement net amount = %d, want 0", total)
+	}
+}
+
+func TestCheckoutFail(t *testing.T) {
+	gateway, client := newGateway(t)
+	gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100})
+	if err := gateway.Fail("T1"); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	if err := gateway.Pay(context.Background(), "T1"); err == nil {
+		t.Error("Pay succeeded on a failed order")
+	}
+	order, _, err := client.QueryOrder(context.Background(), &icbc.OrderQueryRequest{OutTradeNo: "T1"})
+	if err != nil {
+		t.Fatalf("QueryOrder: %v", err)
+	}
+	if order.ResponseBizContent.PayStatus != icbc.PayStatusFailed {
+		t.Errorf("pay_status = %s, want %s", order.ResponseBizContent.PayStatus, icbc.PayStatusFailed)
+	}
+	if entries := gateway.Statement(); len(entries) != 0 {
+		t.Errorf("statement = %+v, want no entries", entries)
+	}
+}
+
+func TestRequestSignatureVerified(t *testing.T) {
+	gateway, _ := newGateway(t)
+	otherKey, _, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100})
+	order, _, err := gateway.Client("APP001", otherKey).QueryOrder(context.Background(), &icbc.OrderQueryRequest{OutTradeNo: "T1"})
+	if err != nil {
+		t.Fatalf("QueryOrder: %v", err)
+	}
+	if order.ResponseBizContent.ReturnCode != icbctest.ReturnCodeSignFailed {
+		t.Errorf("return_code = %s, want %s", order.ResponseBizContent.ReturnCode, icbctest.ReturnCodeSignFailed)
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	tests := []struct {
+		name       string
+		fault      icbctest.Fault
+		timeout    time.Duration
+		check      func(err error) bool
+		returnCode string
+		// 故障失效后第二次请求应恢复正常
+		recovers bool
+	}{
+		{
+			name:  "http status",
+			fault: icbctest.Fault{HTTPStatus: http.StatusBadGateway, Times: 1},
+			check: func(err error) bool {
+				var statusErr *icbc.StatusError
+				return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadGateway
+			},
+			recovers: true,
+		},
+		{
+			name:     "bad signature",
+			fault:    icbctest.Fault{BadSignature: true, Times: 1},
+			check:    func(err error) bool { return errors.Is(err, icbc.ErrSignatureVerification) },
+			recovers: true,
+		},
+		{
+			name:       "return code",
+			fault:      icbctest.Fault{ReturnCode: "9999", ReturnMsg: "system busy"},
+			returnCode: "9999",
+		},
+		{
+			name:    "delay",
+			fault:   icbctest.Fault{Delay: time.Second, Times: 1},
+			timeout: 50 * time.Millisecond,
+			check: func(err error) bool {
+				return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, http.ErrHandlerTimeout) || strings.Contains(err.Error(), "Timeout")
+			},
+			recovers: true,
+		},
+		{
+			name:       "other path unaffected",
+			fault:      icbctest.Fault{Path: icbctest.PathRefund, HTTPStatus: http.StatusBadGateway},
+			returnCode: icbc.ReturnCodeSuccess,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway, client := newGateway(t)
+			gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100})
+			gateway.InjectFault(tt.fault)
+			client.HTTPClient.Timeout = tt.timeout
+
+			order, _, err := client.QueryOrder(context.Background(), &icbc.OrderQueryRequest{OutTradeNo: "T1"})
+			if tt.check != nil {
+				if err == nil || !tt.check(err) {
+					t.Fatalf("QueryOrder error = %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("QueryOrder: %v", err)
+				}
+				if order.ResponseBizContent.ReturnCode != tt.returnCode {
+					t.Errorf("return_code = %s, want %s", order.ResponseBizContent.ReturnCode, tt.returnCode)
+				}
+			}
+			if !tt.recovers {
+				return
+			}
+			client.HTTPClient.Timeout = 0
+			if _, _, err := client.QueryOrder(context.Background(), &icbc.OrderQueryRequest{OutTradeNo: "T1"}); err != nil {
+				t.Errorf("QueryOrder after the fault expired: %v", err)
+			}
+		})
+	}
+}
+
+func TestInjectFaultAfterHandler(t *testing.T) {
+	gateway, client := newGateway(t)
+	gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100, PayStatus: icbc.PayStatusSuccess})
+	gateway.InjectFault(icbctest.Fault{Path: icbctest.PathRefund, Times: 1, AfterHandler: true, HTTPStatus: http.StatusGatewayTimeout})
+
+	_, _, err := client.Refund(context.Background(), &icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R1", RetTotalAmt: "40"})
+	var statusErr *icbc.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusGatewayTimeout {
+		t.Fatalf("Refund error = %v, want status 504", err)
+	}
+	// 响应丢失但退款已经处理
+	order, _ := gateway.Order("T1")
+	if len(order.Refunds) != 1 || order.Refunds[0].PayStatus != icbc.RefundStatusSuccess {
+		t.Errorf("refunds = %+v, want R1 processed", order.Refunds)
+	}
+}
+
+// readBody 读取并关闭响应体
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	var sb strings.Builder
+	if _, err := io.Copy(&sb, resp.Body); err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return sb.String()
+}
@@ -0,0 +1,31 @@
+package icbctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+)
+
+// GenerateKeyPair 生成 RSA-2048 密钥对
+//
+// 返回值:
+//   - string: Base64 编码的 PKCS8 私钥，不含 PEM 开始结束标记
+//   - string: Base64 编码的 PKIX 公钥，不含 PEM 开始结束标记
+//   - error: 错误信息
+func GenerateKeyPair() (string, string, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate rsa key: %w", err)
+	}
+	privateDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	publicDer, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(privateDer), base64.StdEncoding.EncodeToString(publicDer), nil
+}
@@ -0,0 +1,369 @@
+package icbctest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+)
+
+// Order 模拟网关中的订单
+type Order struct {
+	AppID      string
+	MerId      string
+	OutTradeNo string
+	OrderId    string
+	TotalAmt   int64 // 订单金额，单位分
+	PayStatus  string
+	PayTime    string
+	NotifyUrl  string
+	ReturnUrl  string
+	Attach     string
+	Refunds    []Refund
+}
+
+// Refund 模拟网关中的退款记录
+type Refund struct {
+	OuttrxSerialNo string
+	IntrxSerialNo  string
+	RejectAmt      int64 // 退款金额，单位分
+	PayStatus      string
+	RefundTime     string
+}
+
+// refundedAmt 返回订单已退款金额(不含失败的退款)
+func (o *Order) refundedAmt() int64 {
+	var total int64
+	for _, r := range o.Refunds {
+		if r.PayStatus != icbc.RefundStatusFailed {
+			total += r.RejectAmt
+		}
+	}
+	return total
+}
+
+// updateRefundStatus 根据退款记录更新订单状态
+func (o *Order) updateRefundStatus() {
+	for _, r := range o.Refunds {
+		if r.PayStatus == icbc.RefundStatusProcessing {
+			o.PayStatus = icbc.PayStatusRefunding
+			return
+		}
+	}
+	switch refunded := o.refundedAmt(); {
+	case refunded == 0:
+		o.PayStatus = icbc.PayStatusSuccess
+	case refunded >= o.TotalAmt:
+		o.PayStatus = icbc.PayStatusFullRefunded
+	default:
+		o.PayStatus = icbc.PayStatusPartRefunded
+	}
+}
+
+// Order 返回订单快照
+//
+// 参数:
+//   - outTradeNo: 商户订单号
+//
+// 返回值:
+//   - Order: 订单快照
+//   - bool: 订单是否存在
+func (g *Gateway) Order(outTradeNo string) (Order, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	order, ok := g.orders[outTradeNo]
+	if !ok {
+		return Order{}, false
+	}
+	snapshot := *order
+	snapshot.Refunds = append([]Refund(nil), order.Refunds...)
+	return snapshot, true
+}
+
+// AddOrder 直接添加订单，用于跳过下单页面准备测试数据
+//
+// 参数:
+//   - order: 订单，OrderId 为空时自动生成，PayStatus 为空时为支付中
+func (g *Gateway) AddOrder(order Order) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if order.OrderId == "" {
+		order.OrderId = g.nextId("ORD")
+	}
+	if order.PayStatus == "" {
+		order.PayStatus = icbc.PayStatusPaying
+	}
+	g.orders[order.OutTradeNo] = &order
+}
+
+// Pay 将订单标记为支付成功，订单设置了 notify_url 时发送异步通知
+//
+// 参数:
+//   - ctx: 上下文
+//   - outTradeNo: 商户订单号
+//
+// 返回值:
+//   - error: 错误信息
+func (g *Gateway) Pay(ctx context.Context, outTradeNo string) error {
+	g.mu.Lock()
+	order, ok := g.orders[outTradeNo]
+	if !ok {
+		g.mu.Unlock()
+		return fmt.Errorf("order %s not found", outTradeNo)
+	}
+	if order.PayStatus != icbc.PayStatusPaying {
+		g.mu.Unlock()
+		return fmt.Errorf("order %s cannot be paid in status %s", outTradeNo, order.PayStatus)
+	}
+	order.PayStatus = icbc.PayStatusSuccess
+	order.PayTime = icbc.GetCurrentTime()
+	notifyUrl := order.NotifyUrl
+	appID := order.AppID
+	notify := orderNotify(order)
+	g.mu.Unlock()
+
+	if notifyUrl == "" {
+		return nil
+	}
+	return g.SendNotify(ctx, notifyUrl, appID, notify)
+}
+
+// SendNotify 向 notify_url 发送签名后的异步通知
+//
+// 参数:
+//   - ctx: 上下文
+//   - notifyUrl: 通知地址
+//   - appID: 应用ID
+//   - biz: 通知业务内容，如 icbc.Notify
+//
+// 返回值:
+//   - error: 错误信息，商户返回非 200 时同样返回错误
+func (g *Gateway) SendNotify(ctx context.Context, notifyUrl, appID string, biz any) error {
+	u, err := url.Parse(notifyUrl)
+	if err != nil {
+		return fmt.Errorf("failed to parse notify url: %w", err)
+	}
+	bizJson, err := json.Marshal(biz)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notify biz content: %w", err)
+	}
+
+	params := icbc.NewIcbcMap()
+	params.Put("from", "icbc-api")
+	params.Put("api", PathPayUI)
+	params.Put("app_id", appID)
+	params.Put("charset", "UTF-8")
+	params.Put("format", "json")
+	params.Put("timestamp", icbc.GetCurrentTime())
+	params.Put("biz_content", string(bizJson))
+	params.Put("sign_type", "RSA")
+	sign, err := icbc.SignWithSHA1RSA(icbc.BuildOrderedSignStr(params, u.Path), g.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign notify: %w", err)
+	}
+
+	form := url.Values{}
+	for _, k := range []string{"from", "api", "app_id", "charset", "format", "timestamp", "biz_content", "sign_type"} {
+		form.Set(k, params.Get(k))
+	}
+	form.Set("sign", sign)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notifyUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create notify request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := g.NotifyClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notify: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("notify url returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// orderNotify 构建订单支付成功的通知内容
+func orderNotify(order *Order) *icbc.Notify {
+	amt := strconv.FormatInt(order.TotalAmt, 10)
+	return &icbc.Notify{
+		ReturnCode: icbc.ReturnCodeSuccess,
+		ReturnMsg:  "success",
+		MerId:      order.MerId,
+		TotalAmt:   amt,
+		PaymentAmt: amt,
+		OutTradeNo: order.OutTradeNo,
+		OrderId:    order.OrderId,
+		PayTime:    order.PayTime,
+		Attach:     order.Attach,
+	}
+}
+
+// nextId 生成模拟网关内部流水号，调用方需持有锁
+func (g *Gateway) nextId(prefix string) string {
+	g.seq++
+	return fmt.Sprintf("%s%012d", prefix, g.seq)
+}
+
+// findOrder 按 out_trade_no 或 order_id 查找订单，调用方需持有锁
+func (g *Gateway) findOrder(outTradeNo, orderId string) *Order {
+	if outTradeNo != "" {
+		return g.orders[outTradeNo]
+	}
+	for _, order := range g.orders {
+		if orderId != "" && order.OrderId == orderId {
+			return order
+		}
+	}
+	return nil
+}
+
+// handleOrderQuery 处理订单查询
+func (g *Gateway) handleOrderQuery(req *Request) (any, error) {
+	var query icbc.OrderQueryRequest
+	if err := json.Unmarshal(req.BizContent, &query); err != nil {
+		return nil, fmt.Errorf("invalid biz_content: %w", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	order := g.findOrder(query.OutTradeNo, query.OrderId)
+	if order == nil {
+		return &BizError{ReturnCode: ReturnCodeOrderNotFound, ReturnMsg: "order not found", MsgId: req.MsgID}, nil
+	}
+
+	var resp icbc.OrderQueryResp
+	biz := &resp.ResponseBizContent
+	biz.ReturnCode = icbc.ReturnCodeSuccess
+	biz.ReturnMsg = "success"
+	biz.MsgId = req.MsgID
+	biz.PayStatus = order.PayStatus
+	biz.MerId = order.MerId
+	biz.OutTradeNo = order.OutTradeNo
+	biz.OrderId = order.OrderId
+	biz.TotalAmt = strconv.FormatInt(order.TotalAmt, 10)
+	biz.PaymentAmt = biz.TotalAmt
+	biz.PayTime = order.PayTime
+	biz.Attach = order.Attach
+	return biz, nil
+}
+
+// handleRefund 处理退款，相同 outtrx_serial_no 的重复请求返回首次结果
+func (g *Gateway) handleRefund(req *Request) (any, error) {
+	var refundReq icbc.RefundRequest
+	if err := json.Unmarshal(req.BizContent, &refundReq); err != nil {
+		return nil, fmt.Errorf("invalid biz_content: %w", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	order := g.findOrder(refundReq.OutTradeNo, refundReq.OrderId)
+	if order == nil {
+		return &BizError{ReturnCode: ReturnCodeOrderNotFound, ReturnMsg: "order not found", MsgId: req.MsgID}, nil
+	}
+	for _, refund := range order.Refunds {
+		if refund.OuttrxSerialNo == refundReq.OuttrxSerialNo {
+			return refundResponse(req.MsgID, order, &refund), nil
+		}
+	}
+
+	switch order.PayStatus {
+	case icbc.PayStatusSuccess, icbc.PayStatusPartRefunded, icbc.PayStatusRefunding:
+	default:
+		return &BizError{ReturnCode: ReturnCodeInvalidState, ReturnMsg: "order status " + order.PayStatus + " cannot be refunded", MsgId: req.MsgID}, nil
+	}
+	amt, err := strconv.ParseInt(refundReq.RetTotalAmt, 10, 64)
+	if err != nil || amt <= 0 || amt > order.TotalAmt-order.refundedAmt() {
+		return &BizError{ReturnCode: ReturnCodeInvalidAmount, ReturnMsg: "invalid ret_total_amt " + refundReq.RetTotalAmt, MsgId: req.MsgID}, nil
+	}
+
+	status := g.NewRefundStatus
+	if status == "" {
+		status = icbc.RefundStatusSuccess
+	}
+	refund := Refund{
+		OuttrxSerialNo: refundReq.OuttrxSerialNo,
+		IntrxSerialNo:  g.nextId("RFD"),
+		RejectAmt:      amt,
+		PayStatus:      status,
+		RefundTime:     icbc.GetCurrentTime(),
+	}
+	order.Refunds = append(order.Refunds, refund)
+	order.updateRefundStatus()
+	return refundResponse(req.MsgID, order, &refund), nil
+}
+
+// refundResponse 构建退款接口响应内容，只有退款成功时返回码为成功
+//
+// 退款处理中返回 ReturnCodeRefunding、失败返回 ReturnCodeRefundFailed，客户端需通过退款查询确认结果。
+func refundResponse(msgId string, order *Order, refund *Refund) any {
+	var resp icbc.RefundResp
+	biz := &resp.ResponseBizContent
+	switch refund.PayStatus {
+	case icbc.RefundStatusProcessing:
+		biz.ReturnCode = ReturnCodeRefunding
+		biz.ReturnMsg = "refund processing"
+	case icbc.RefundStatusFailed:
+		biz.ReturnCode = ReturnCodeRefundFailed
+		biz.ReturnMsg = "refund failed"
+	default:
+		biz.ReturnCode = icbc.ReturnCodeSuccess
+		biz.ReturnMsg = "success"
+	}
+	biz.MsgId = msgId
+	biz.OutTradeNo = order.OutTradeNo
+	biz.OrderId = order.OrderId
+	biz.OuttrxSerialNo = refund.OuttrxSerialNo
+	biz.IntrxSerialNo = refund.IntrxSerialNo
+	biz.RejectAmt = strconv.FormatInt(refund.RejectAmt, 10)
+	biz.RealRejectAmt = biz.RejectAmt
+	biz.RefundTime = refund.RefundTime
+	return biz
+}
+
+// handleRefundQuery 处理退款查询
+func (g *Gateway) handleRefundQuery(req *Request) (any, error) {
+	var query icbc.QueryRefundRequest
+	if err := json.Unmarshal(req.BizContent, &query); err != nil {
+		return nil, fmt.Errorf("invalid biz_content: %w", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	order := g.findOrder(query.OutTradeNo, query.OrderId)
+	if order == nil {
+		return &BizError{ReturnCode: ReturnCodeOrderNotFound, ReturnMsg: "order not found", MsgId: req.MsgID}, nil
+	}
+	for _, refund := range order.Refunds {
+		if refund.OuttrxSerialNo != query.OuttrxSerialNo {
+			continue
+		}
+		amt := strconv.FormatInt(refund.RejectAmt, 10)
+		return &icbc.QueryRefundResponse{
+			ReturnCode:     icbc.ReturnCodeSuccess,
+			ReturnMsg:      "success",
+			PayStatus:      refund.PayStatus,
+			MsgId:          req.MsgID,
+			OutTradeNo:     order.OutTradeNo,
+			OrderId:        order.OrderId,
+			OuttrxSerialNo: refund.OuttrxSerialNo,
+			IntrxSerialNo:  refund.IntrxSerialNo,
+			RejectAmt:      amt,
+			RealRejectAmt:  amt,
+			RefundTime:     refund.RefundTime,
+		}, nil
+	}
+	return &BizError{ReturnCode: ReturnCodeOrderNotFound, ReturnMsg: "refund not found", MsgId: req.MsgID}, nil
+}
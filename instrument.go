@@ -0,0 +1,118 @@
+package icbc_api_sdk_go
+
+import (
+	"context"
+	"errors"
+	URL "net/url"
+	"time"
+)
+
+// Attribute 链路追踪属性
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Tracer 链路追踪接口，可适配 OpenTelemetry 等实现，SDK 本身不依赖任何追踪库
+type Tracer interface {
+	// Start 创建一个 span，返回携带该 span 的 context
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span 链路追踪 span
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Metrics 指标接口，可适配 OpenTelemetry、Prometheus 等实现
+type Metrics interface {
+	// RecordCall 记录一次接口调用的次数和耗时，err 为 nil 表示调用成功(不含业务错误)
+	RecordCall(ctx context.Context, path string, latency time.Duration, err error)
+	// RecordSignatureFailure 记录一次响应验签失败
+	RecordSignatureFailure(ctx context.Context, path string)
+	// RecordBusinessError 记录一次 return_code 非成功的业务错误
+	RecordBusinessError(ctx context.Context, path string, returnCode string)
+}
+
+// 链路追踪属性名
+const (
+	AttrAPIPath    = "icbc.api.path"
+	AttrAppID      = "icbc.app_id"
+	AttrMsgID      = "icbc.msg_id"
+	AttrReturnCode = "icbc.return_code"
+	AttrAttempts   = "icbc.retry.attempts"
+)
+
+// NewInstrumentationInterceptor 创建链路追踪与指标拦截器
+//
+// 每次调用创建一个 span，并记录调用次数、耗时、验签失败和业务错误。tracer 或 metrics 为 nil 时跳过对应功能。
+// 建议将其放在 DefaultClient.Interceptors 的第一个，以覆盖其他拦截器的耗时。
+//
+// 参数:
+//   - tracer: 链路追踪实现，可为 nil
+//   - metrics: 指标实现，可为 nil
+//
+// 返回值:
+//   - Interceptor: 拦截器
+func NewInstrumentationInterceptor(tracer Tracer, metrics Metrics) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) error {
+		path := apiPath(call.Request.ServiceUrl)
+
+		var span Span
+		if tracer != nil {
+			ctx, span = tracer.Start(ctx, "icbc "+path,
+				Attribute{Key: AttrAPIPath, Value: path},
+				Attribute{Key: AttrAppID, Value: call.Params.Get("app_id")},
+				Attribute{Key: AttrMsgID, Value: call.Params.Get("msg_id")},
+			)
+			defer span.End()
+			// 让 HTTP 传输层(如 otelhttp)能拿到当前 span
+			call.HTTPRequest = call.HTTPRequest.WithContext(ctx)
+		}
+
+		start := time.Now()
+		err := next(ctx, call)
+		latency := time.Since(start)
+
+		returnCode := ""
+		if call.DecodedBody != nil {
+			returnCode = extractReturnCode(call.DecodedBody)
+		}
+		if span != nil {
+			span.SetAttributes(
+				Attribute{Key: AttrAttempts, Value: call.Attempts},
+				Attribute{Key: AttrReturnCode, Value: returnCode},
+			)
+			if err != nil {
+				span.RecordError(err)
+			}
+		}
+		if metrics != nil {
+			metrics.RecordCall(ctx, path, latency, err)
+			if errors.Is(err, ErrSignatureVerification) {
+				metrics.RecordSignatureFailure(ctx, path)
+			}
+			if err == nil && returnCode != "" && returnCode != ReturnCodeSuccess {
+				metrics.RecordBusinessError(ctx, path, returnCode)
+			}
+		}
+		return err
+	}
+}
+
+// apiPath 从服务地址中提取接口路径
+//
+// 参数:
+//   - serviceUrl: 服务地址
+//
+// 返回值:
+//   - string: 接口路径，解析失败时返回原地址
+func apiPath(serviceUrl string) string {
+	u, err := URL.Parse(serviceUrl)
+	if err != nil || u.Path == "" {
+		return serviceUrl
+	}
+	return u.Path
+}
@@ -0,0 +1,70 @@
+package icbc_api_sdk_go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Call 一次接口调用的信息，在拦截器链中传递
+//
+// 调用 next 之前可读取 Request、Params 并修改 HTTPRequest(如注入请求头)；
+// next 返回之后可读取 Attempts、StatusCode、Body、DecodedBody、BizContent、Verified 和 VerifyErr。
+type Call struct {
+	Request     *ICBCRequest    // 原始请求对象
+	Params      *IcbcMap        // 已签名的请求参数
+	HTTPRequest *http.Request   // 即将发送的HTTP请求，重试时会复制其请求头
+	Attempts    int             // 实际发送次数(包含重试)
+	StatusCode  int             // 最后一次请求的HTTP状态码，网络错误时为 0
+	Body        []byte          // 原始响应体，为客户端字符集(如 GBK)的原始字节，与验签使用的字节一致；请求失败时为 nil
+	DecodedBody []byte          // 解码为 UTF-8 的响应体，字符集为 UTF-8 时与 Body 相同；请求失败时为 nil
+	BizContent  json.RawMessage // 验签通过的 response_biz_content 原文
+	Verified    bool            // 响应是否通过验签
+	VerifyErr   error           // 验签失败原因
+
+	sign string // 验签通过的响应签名
+}
+
+// CallResult ExecuteCall 返回的调用信息
+type CallResult struct {
+	MsgID      string          // 本次请求的 msg_id，可用于向工行查询调用记录
+	Timestamp  string          // 请求的 timestamp 参数
+	SignString string          // 待签名字符串
+	Sign       string          // 请求签名
+	StatusCode int             // HTTP状态码，网络错误时为 0
+	Attempts   int             // 实际发送次数(包含重试)
+	Latency    time.Duration   // 调用耗时，包含重试和拦截器
+	BizContent json.RawMessage // 验签通过的 response_biz_content 原文，验签失败时为 nil
+}
+
+// Invoker 执行一次接口调用
+type Invoker func(ctx context.Context, call *Call) error
+
+// Interceptor 拦截器，类似 http.RoundTripper 包裹每次接口调用
+//
+// 拦截器必须调用 next 才会继续执行后续拦截器和实际请求，也可以直接返回错误中止调用。
+type Interceptor func(ctx context.Context, call *Call, next Invoker) error
+
+// chainInterceptors 将拦截器按顺序组合为一个 Invoker，第一个拦截器位于最外层
+//
+// 参数:
+//   - interceptors: 拦截器列表
+//   - final: 拦截器链末端的实际调用
+//
+// 返回值:
+//   - Invoker: 组合后的调用
+func chainInterceptors(interceptors []Interceptor, final Invoker) Invoker {
+	invoker := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := invoker
+		if interceptor == nil {
+			continue
+		}
+		invoker = func(ctx context.Context, call *Call) error {
+			return interceptor(ctx, call, next)
+		}
+	}
+	return invoker
+}
@@ -0,0 +1,82 @@
+package icbc_api_sdk_go_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestInterceptorOrder(t *testing.T) {
+	gateway, client := newGatewayClient(t)
+	gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100})
+
+	var trace []string
+	record := func(name string) icbc.Interceptor {
+		return func(ctx context.Context, call *icbc.Call, next icbc.Invoker) error {
+			trace = append(trace, name+" before")
+			err := next(ctx, call)
+			trace = append(trace, name+" after")
+			return err
+		}
+	}
+	client.Interceptors = []icbc.Interceptor{record("a"), nil, record("b")}
+
+	if _, _, err := client.QueryOrder(context.Background(), &icbc.OrderQueryRequest{OutTradeNo: "T1"}); err != nil {
+		t.Fatalf("QueryOrder: %v", err)
+	}
+	if got, want := strings.Join(trace, ", "), "a before, b before, b after, a after"; got != want {
+		t.Errorf("interceptor order = %s, want %s", got, want)
+	}
+}
+
+func TestInterceptorAbort(t *testing.T) {
+	gateway, client := newGatewayClient(t)
+	errDenied := errors.New("denied")
+	client.Interceptors = []icbc.Interceptor{func(ctx context.Context, call *icbc.Call, next icbc.Invoker) error {
+		return errDenied
+	}}
+
+	_, _, err := client.QueryOrder(context.Background(), &icbc.OrderQueryRequest{OutTradeNo: "T1"})
+	if !errors.Is(err, errDenied) {
+		t.Errorf("QueryOrder error = %v, want %v", err, errDenied)
+	}
+	if n := len(gateway.Requests()); n != 0 {
+		t.Errorf("gateway received %d requests, want 0", n)
+	}
+}
+
+func TestInterceptorSeesRawBody(t *testing.T) {
+	msg, err := simplifiedchinese.GBK.NewEncoder().String("成功")
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	var body []byte
+	client, gatewayKey := newStaticClient(t, func() []byte { return body })
+	client.Charset = icbc.CharsetGBK
+	body = signedResponse(t, gatewayKey, `{"return_code":"0","return_msg":"`+msg+`"}`, "")
+
+	var call *icbc.Call
+	client.Interceptors = []icbc.Interceptor{func(ctx context.Context, c *icbc.Call, next icbc.Invoker) error {
+		call = c
+		return next(ctx, c)
+	}}
+	var resp icbc.IcbcResponse
+	if _, err := client.ExecuteWithContext(context.Background(), &icbc.ICBCRequest{ServiceUrl: "https://gw.example.com" + icbc.PathOrderQuery}, "", &resp); err != nil {
+		t.Fatalf("ExecuteWithContext: %v", err)
+	}
+	if !bytes.Equal(call.Body, body) {
+		t.Errorf("Call.Body = %q, want the raw GBK response %q", call.Body, body)
+	}
+	if !bytes.Contains(call.DecodedBody, []byte("成功")) {
+		t.Errorf("Call.DecodedBody = %q, want UTF-8 return_msg", call.DecodedBody)
+	}
+	if !call.Verified || !bytes.Contains(call.BizContent, []byte("成功")) {
+		t.Errorf("Call.Verified = %v, BizContent = %s", call.Verified, call.BizContent)
+	}
+}
@@ -0,0 +1,267 @@
+package icbc_api_sdk_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyRing 可在运行时原子切换的密钥集合，用于商户私钥和工行网关公钥的无停机轮换
+//
+// 签名始终使用当前私钥；验签依次尝试所有工行网关公钥，任意一个通过即视为验签成功，
+// 因此轮换期间可同时配置新旧两把公钥。零值的 KeyRing 不包含任何密钥，签名时返回错误，
+// 可随后通过 SetPrivateKey、SetIcbcPublicKeys 或 LoadFiles 加载密钥。
+type KeyRing struct {
+	keys atomic.Pointer[keySet]
+	mu   sync.Mutex // 串行化写操作，避免并发更新互相覆盖
+}
+
+// keySet 某一时刻的密钥快照，创建后不再修改
+type keySet struct {
+	privateKey     string
+	icbcPublicKeys []string
+}
+
+// NewKeyRing 创建密钥集合
+//
+// 参数:
+//   - privateKey: 商户私钥
+//   - icbcPublicKeys: 工行网关公钥，至少一个
+//
+// 返回值:
+//   - *KeyRing: 密钥集合
+//   - error: 密钥无法解析时返回错误
+func NewKeyRing(privateKey string, icbcPublicKeys ...string) (*KeyRing, error) {
+	if err := validatePrivateKey(privateKey); err != nil {
+		return nil, err
+	}
+	if err := validatePublicKeys(icbcPublicKeys); err != nil {
+		return nil, err
+	}
+	k := &KeyRing{}
+	k.keys.Store(&keySet{privateKey: privateKey, icbcPublicKeys: append([]string(nil), icbcPublicKeys...)})
+	return k, nil
+}
+
+// DefaultKeyWatchInterval WatchFiles 的默认检查间隔
+const DefaultKeyWatchInterval = 30 * time.Second
+
+// snapshot 返回当前的密钥快照，尚未加载密钥时返回空快照
+func (k *KeyRing) snapshot() *keySet {
+	if keys := k.keys.Load(); keys != nil {
+		return keys
+	}
+	return &keySet{}
+}
+
+// PrivateKey 返回当前的商户私钥
+//
+// 返回值:
+//   - string: 商户私钥，尚未加载时为空字符串
+func (k *KeyRing) PrivateKey() string {
+	return k.snapshot().privateKey
+}
+
+// IcbcPublicKeys 返回当前的工行网关公钥
+//
+// 返回值:
+//   - []string: 工行网关公钥的副本，尚未加载时为 nil
+func (k *KeyRing) IcbcPublicKeys() []string {
+	return append([]string(nil), k.snapshot().icbcPublicKeys...)
+}
+
+// SetPrivateKey 校验并切换商户私钥，切换后的请求立即使用新私钥签名
+//
+// 参数:
+//   - privateKey: 新的商户私钥
+//
+// 返回值:
+//   - error: 私钥无法解析时返回错误，此时保持原私钥不变
+func (k *KeyRing) SetPrivateKey(privateKey string) error {
+	if err := validatePrivateKey(privateKey); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	current := k.snapshot()
+	k.keys.Store(&keySet{privateKey: privateKey, icbcPublicKeys: current.icbcPublicKeys})
+	return nil
+}
+
+// SetIcbcPublicKeys 校验并替换工行网关公钥
+//
+// 参数:
+//   - icbcPublicKeys: 新的工行网关公钥，至少一个
+//
+// 返回值:
+//   - error: 公钥无法解析时返回错误，此时保持原公钥不变
+func (k *KeyRing) SetIcbcPublicKeys(icbcPublicKeys ...string) error {
+	if err := validatePublicKeys(icbcPublicKeys); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	current := k.snapshot()
+	k.keys.Store(&keySet{privateKey: current.privateKey, icbcPublicKeys: append([]string(nil), icbcPublicKeys...)})
+	return nil
+}
+
+// LoadFiles 从文件读取商户私钥和工行网关公钥，全部校验通过后一次性切换
+//
+// 参数:
+//   - privateKeyFile: 商户私钥文件
+//   - icbcPublicKeyFiles: 工行网关公钥文件，至少一个
+//
+// 返回值:
+//   - error: 读取或校验失败时返回错误，此时保持原密钥不变
+func (k *KeyRing) LoadFiles(privateKeyFile string, icbcPublicKeyFiles ...string) error {
+	privateKey, err := readKeyFile("", privateKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read private key: %w", err)
+	}
+	if err := validatePrivateKey(privateKey); err != nil {
+		return fmt.Errorf("%s: %w", privateKeyFile, err)
+	}
+	publicKeys := make([]string, 0, len(icbcPublicKeyFiles))
+	for _, file := range icbcPublicKeyFiles {
+		publicKey, err := readKeyFile("", file)
+		if err != nil {
+			return fmt.Errorf("failed to read icbc public key: %w", err)
+		}
+		publicKeys = append(publicKeys, publicKey)
+	}
+	if err := validatePublicKeys(publicKeys); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys.Store(&keySet{privateKey: privateKey, icbcPublicKeys: publicKeys})
+	return nil
+}
+
+// WatchFiles 定期检查密钥文件的修改时间，发生变化时调用 LoadFiles 重新加载，直到 ctx 结束
+//
+// 该方法会阻塞，通常在单独的 goroutine 中运行。重新加载失败时保持原密钥不变，并在下次变化时重试。
+//
+// 参数:
+//   - ctx: 上下文，结束时停止检查
+//   - interval: 检查间隔，小于等于 0 时使用 DefaultKeyWatchInterval
+//   - onReload: 每次重新加载后的回调，err 为 nil 表示加载成功，可为 nil
+//   - privateKeyFile: 商户私钥文件
+//   - icbcPublicKeyFiles: 工行网关公钥文件
+func (k *KeyRing) WatchFiles(ctx context.Context, interval time.Duration, onReload func(err error), privateKeyFile string, icbcPublicKeyFiles ...string) {
+	files := append([]string{privateKeyFile}, icbcPublicKeyFiles...)
+	last := fileModTimes(files)
+	if interval <= 0 {
+		interval = DefaultKeyWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current := fileModTimes(files)
+		if current == last {
+			continue
+		}
+		last = current
+		err := k.LoadFiles(privateKeyFile, icbcPublicKeyFiles...)
+		if onReload != nil {
+			onReload(err)
+		}
+	}
+}
+
+// fileModTimes 返回文件修改时间和大小的摘要，文件不存在时记为空
+func fileModTimes(files []string) string {
+	var b strings.Builder
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			fmt.Fprintf(&b, "%d:%d;", info.ModTime().UnixNano(), info.Size())
+		} else {
+			b.WriteString("-;")
+		}
+	}
+	return b.String()
+}
+
+// validatePrivateKey 校验商户私钥可以解析
+func validatePrivateKey(privateKey string) error {
+	if _, err := parseRSAPrivateKey(privateKey); err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	return nil
+}
+
+// validatePublicKeys 校验至少有一个公钥且全部可以解析
+func validatePublicKeys(publicKeys []string) error {
+	if len(publicKeys) == 0 {
+		return errors.New("at least one icbc public key is required")
+	}
+	for i, publicKey := range publicKeys {
+		if _, err := parseRSAPublicKey(publicKey); err != nil {
+			return fmt.Errorf("invalid icbc public key #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// signingKey 返回签名使用的商户私钥，配置了 Keys 时优先使用
+func (c *DefaultClient) signingKey() (string, error) {
+	privateKey := c.PrivateKey
+	if c.Keys != nil {
+		privateKey = c.Keys.PrivateKey()
+	}
+	if privateKey == "" {
+		return "", fmt.Errorf("private key is not configured")
+	}
+	return privateKey, nil
+}
+
+// verifyingKeys 返回验签使用的工行网关公钥，配置了 Keys 时优先使用
+func (c *DefaultClient) verifyingKeys() []string {
+	if c.Keys != nil {
+		return c.Keys.IcbcPublicKeys()
+	}
+	return append([]string{c.IcbcPublicKey}, c.IcbcPublicKeys...)
+}
+
+// verifySignature 依次使用每个工行网关公钥验签，任意一个通过即返回成功
+//
+// 参数:
+//   - verify: 验签函数，如 VerifySHA1RSA
+//   - data: 待验签数据
+//   - sign: 签名
+//
+// 返回值:
+//   - error: 全部公钥验签失败时返回包装 ErrSignatureVerification 的错误
+func (c *DefaultClient) verifySignature(verify func(data, signature, publicKey string) (bool, error), data, sign string) error {
+	var lastErr error
+	tried := 0
+	for _, publicKey := range c.verifyingKeys() {
+		if publicKey == "" {
+			continue
+		}
+		tried++
+		pass, err := verify(data, sign, publicKey)
+		if err == nil && pass {
+			return nil
+		}
+		lastErr = err
+	}
+	if tried == 0 {
+		return fmt.Errorf("%w: icbc public key is not configured", ErrSignatureVerification)
+	}
+	if lastErr != nil {
+		return fmt.Errorf("%w: %w", ErrSignatureVerification, lastErr)
+	}
+	return ErrSignatureVerification
+}
@@ -0,0 +1,140 @@
+package icbc_api_sdk_go_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+)
+
+// generateKeyPair 生成测试用的 RSA 密钥对
+func generateKeyPair(t *testing.T) (privateKey, publicKey string) {
+	t.Helper()
+	privateKey, publicKey, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	return privateKey, publicKey
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	gateway, client := newGatewayClient(t)
+	gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100})
+	keys, err := icbc.NewKeyRing(client.PrivateKey, gateway.PublicKey)
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	client.PrivateKey, client.IcbcPublicKey = "", ""
+	client.Keys = keys
+	query := func() (*icbc.OrderQueryResp, error) {
+		resp, _, err := client.QueryOrder(context.Background(), &icbc.OrderQueryRequest{OutTradeNo: "T1"})
+		return resp, err
+	}
+
+	oldGatewayPub := gateway.PublicKey
+	newGatewayKey, newGatewayPub := generateKeyPair(t)
+	newMerchantKey, newMerchantPub := generateKeyPair(t)
+	steps := []struct {
+		name       string
+		rotate     func() error
+		wantErr    error
+		returnCode string
+	}{
+		{"initial keys", func() error { return nil }, nil, icbc.ReturnCodeSuccess},
+		{"gateway signs with new key", func() error {
+			gateway.PrivateKey, gateway.PublicKey = newGatewayKey, newGatewayPub
+			return nil
+		}, icbc.ErrSignatureVerification, ""},
+		{"both gateway keys accepted", func() error { return keys.SetIcbcPublicKeys(oldGatewayPub, newGatewayPub) }, nil, icbc.ReturnCodeSuccess},
+		{"invalid private key rejected", func() error {
+			if err := keys.SetPrivateKey("invalid"); err == nil {
+				return errors.New("SetPrivateKey accepted an invalid key")
+			}
+			return nil
+		}, nil, icbc.ReturnCodeSuccess},
+		{"merchant key rotated before gateway", func() error { return keys.SetPrivateKey(newMerchantKey) }, nil, icbctest.ReturnCodeSignFailed},
+		{"gateway accepts new merchant key", func() error {
+			gateway.MerchantPublicKey = newMerchantPub
+			return nil
+		}, nil, icbc.ReturnCodeSuccess},
+		{"old gateway key retired", func() error { return keys.SetIcbcPublicKeys(newGatewayPub) }, nil, icbc.ReturnCodeSuccess},
+	}
+	for _, step := range steps {
+		if err := step.rotate(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		resp, err := query()
+		if step.wantErr != nil {
+			if !errors.Is(err, step.wantErr) {
+				t.Errorf("%s: error = %v, want %v", step.name, err, step.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: QueryOrder: %v", step.name, err)
+		}
+		if resp.ResponseBizContent.ReturnCode != step.returnCode {
+			t.Errorf("%s: return_code = %s, want %s", step.name, resp.ResponseBizContent.ReturnCode, step.returnCode)
+		}
+	}
+}
+
+func TestKeyRingZeroValue(t *testing.T) {
+	gateway, client := newGatewayClient(t)
+	client.Keys = &icbc.KeyRing{}
+	if _, _, err := client.QueryOrder(context.Background(), &icbc.OrderQueryRequest{OutTradeNo: "T1"}); err == nil {
+		t.Error("QueryOrder with an empty KeyRing succeeded, want error")
+	}
+	if n := len(gateway.Requests()); n != 0 {
+		t.Errorf("gateway received %d requests, want 0", n)
+	}
+}
+
+func TestKeyRingWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	privateKey, _ := generateKeyPair(t)
+	_, oldPub := generateKeyPair(t)
+	_, newPub := generateKeyPair(t)
+	privateFile := filepath.Join(dir, "merchant_private_key.txt")
+	publicFile := filepath.Join(dir, "icbc_public_key.txt")
+	for file, key := range map[string]string{privateFile: privateKey, publicFile: oldPub} {
+		if err := os.WriteFile(file, []byte(key), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	keys := &icbc.KeyRing{}
+	if err := keys.LoadFiles(privateFile, publicFile); err != nil {
+		t.Fatalf("LoadFiles: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reloaded := make(chan error, 10)
+	go keys.WatchFiles(ctx, 5*time.Millisecond, func(err error) { reloaded <- err }, privateFile, publicFile)
+
+	// 等待第一次检查完成后再修改文件
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(publicFile, []byte(newPub), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("reload failed: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("WatchFiles did not reload the changed key file")
+	}
+	if got := keys.IcbcPublicKeys(); !slices.Equal(got, []string{newPub}) {
+		t.Errorf("IcbcPublicKeys = %v, want the rewritten key", got)
+	}
+	if keys.PrivateKey() != privateKey {
+		t.Error("private key changed after reloading the public key file")
+	}
+}
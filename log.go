@@ -0,0 +1,183 @@
+package icbc_api_sdk_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// RedactedValue 脱敏后的占位值
+const RedactedValue = "***"
+
+// RedactedFields 日志中需要脱敏的字段名，同时作用于请求参数和 biz_content/响应中的 JSON 字段
+var RedactedFields = []string{
+	"sign",
+	"private_key",
+	"card_no",
+	"cust_cert_no",
+	"open_id",
+	"openId",
+}
+
+// discardLogger 未配置 Logger 时使用的空日志
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// logger 返回客户端日志，未配置时返回丢弃所有日志的 Logger
+//
+// 返回值:
+//   - *slog.Logger: 日志
+func (c *DefaultClient) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return discardLogger
+}
+
+// LogValue 实现 slog.LogValuer，避免客户端被直接打印到日志时泄露私钥
+//
+// 返回值:
+//   - slog.Value: 仅包含 app_id 和 sign_type 的日志值
+func (c *DefaultClient) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("app_id", c.APPID),
+		slog.String("sign_type", c.SignType),
+		slog.String("private_key", RedactedValue),
+	)
+}
+
+// logCall 记录一次接口调用的请求与响应事件
+//
+// 参数:
+//   - ctx: 上下文
+//   - call: 调用信息
+//   - latency: 调用耗时
+//   - err: 调用错误
+func (c *DefaultClient) logCall(ctx context.Context, call *Call, latency time.Duration, err error) {
+	logger := c.logger()
+	attrs := []slog.Attr{
+		slog.String("service_url", call.Request.ServiceUrl),
+		slog.String("msg_id", call.Params.Get("msg_id")),
+	}
+
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.LogAttrs(ctx, slog.LevelDebug, "icbc request",
+			append(attrs, slog.Any("params", RedactParams(call.Params)))...)
+	}
+
+	attrs = append(attrs,
+		slog.Duration("latency", latency),
+		slog.Int("attempts", call.Attempts),
+	)
+	if call.DecodedBody != nil {
+		attrs = append(attrs, slog.String("return_code", extractReturnCode(call.DecodedBody)))
+	}
+	if err != nil {
+		logger.LogAttrs(ctx, slog.LevelError, "icbc request failed",
+			append(attrs, slog.String("error", err.Error()))...)
+		return
+	}
+	logger.LogAttrs(ctx, slog.LevelInfo, "icbc response", attrs...)
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.LogAttrs(ctx, slog.LevelDebug, "icbc response body",
+			append(attrs, slog.String("body", RedactJSON(call.DecodedBody)))...)
+	}
+}
+
+// RedactParams 返回脱敏后的请求参数副本，biz_content 中的敏感字段同样会被脱敏
+//
+// 参数:
+//   - params: 请求参数
+//
+// 返回值:
+//   - map[string]string: 脱敏后的参数
+func RedactParams(params *IcbcMap) map[string]string {
+	redacted := make(map[string]string)
+	if params == nil {
+		return redacted
+	}
+	for k, v := range params.data {
+		switch {
+		case slices.Contains(RedactedFields, k):
+			redacted[k] = RedactedValue
+		case k == "biz_content":
+			redacted[k] = RedactJSON([]byte(v))
+		default:
+			redacted[k] = v
+		}
+	}
+	return redacted
+}
+
+// RedactJSON 返回将敏感字段替换为占位值后的 JSON 字符串
+//
+// 参数:
+//   - data: JSON 数据
+//
+// 返回值:
+//   - string: 脱敏后的 JSON；无法解析时不输出原文，只返回长度说明
+func RedactJSON(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Sprintf("<unparsable json, %d bytes>", len(data))
+	}
+	redacted, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return fmt.Sprintf("<unparsable json, %d bytes>", len(data))
+	}
+	return string(redacted)
+}
+
+// redactValue 递归脱敏 JSON 值
+func redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if slices.Contains(RedactedFields, k) {
+				val[k] = RedactedValue
+			} else {
+				val[k] = redactValue(item)
+			}
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = redactValue(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// extractReturnCode 从原始响应体中提取 response_biz_content.return_code
+//
+// 参数:
+//   - body: 原始响应体
+//
+// 返回值:
+//   - string: 返回码，无法解析时为空字符串
+func extractReturnCode(body []byte) string {
+	var resp struct {
+		ResponseBizContent struct {
+			ReturnCode json.RawMessage `json:"return_code"`
+		} `json:"response_biz_content"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ""
+	}
+	code := resp.ResponseBizContent.ReturnCode
+	var s string
+	if err := json.Unmarshal(code, &s); err == nil {
+		return s
+	}
+	return string(code)
+}
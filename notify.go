@@ -1,5 +1,12 @@
 package icbc_api_sdk_go
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	URL "net/url"
+)
+
 type Notify struct {
 	ReturnCode   string `json:"return_code"`
 	ReturnMsg    string `json:"return_msg"`
@@ -27,3 +34,120 @@ type Notify struct {
 	CardKind     string `json:"card_kind"`
 	BankType     string `json:"bank_type"`
 }
+
+// ParseNotify 解析并验签工行异步通知请求
+//
+// 参数:
+//   - r: 工行回调商户 notify_url 的HTTP请求
+//
+// 返回值:
+//   - *Notify: 通知内容
+//   - error: 错误信息
+func (c *DefaultClient) ParseNotify(r *http.Request) (*Notify, error) {
+	if r == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("failed to parse notify form: %w", err)
+	}
+	return c.VerifyNotify(r.URL.Path, r.PostForm)
+}
+
+// VerifyNotify 验签工行异步通知参数并解析 biz_content
+//
+// 通知的待签名字符串为 notify_url 的路径加上除 sign 外按参数名排序的参数，
+// sign_type 为 RSA2 时使用 SHA-256 验签，否则使用 SHA-1 验签。
+// 验签使用参数的原始字节，biz_content 按通知的 charset 参数解码，未携带时使用客户端的 Charset。
+//
+// 参数:
+//   - path: notify_url 的路径
+//   - form: 通知的表单参数
+//
+// 返回值:
+//   - *Notify: 通知内容
+//   - error: 错误信息
+func (c *DefaultClient) VerifyNotify(path string, form URL.Values) (*Notify, error) {
+	sign := form.Get("sign")
+	if sign == "" {
+		return nil, fmt.Errorf("notify sign cannot be empty")
+	}
+
+	params := NewIcbcMap()
+	for k := range form {
+		if k != "sign" {
+			params.Put(k, form.Get(k))
+		}
+	}
+	signStr := BuildOrderedSignStr(params, path)
+
+	verify := VerifySHA1RSA
+	if form.Get("sign_type") == SignTypeRSA2 {
+		verify = VerifySHA256RSA
+	}
+	if err := c.verifySignature(verify, signStr, sign); err != nil {
+		return nil, err
+	}
+
+	charset := form.Get("charset")
+	if charset == "" {
+		charset = c.charset()
+	}
+	if err := validateCharset(charset); err != nil {
+		return nil, err
+	}
+	bizContent, err := decodeCharset(charset, []byte(form.Get("biz_content")))
+	if err != nil {
+		return nil, err
+	}
+
+	notify := &Notify{}
+	if err := json.Unmarshal(bizContent, notify); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal notify biz content: %w", err)
+	}
+	return notify, nil
+}
+
+// BuildNotifyResponse 构建商户对异步通知的签名应答
+//
+// 参数:
+//   - returnCode: 返回码，0 表示处理成功
+//   - returnMsg: 返回信息
+//   - msgId: 通知中的 msg_id
+//
+// 返回值:
+//   - string: 应答 JSON 字符串，按客户端的 Charset 编码，应原样写入HTTP响应
+//   - error: 错误信息
+func (c *DefaultClient) BuildNotifyResponse(returnCode int, returnMsg, msgId string) (string, error) {
+	bizContent, err := json.Marshal(struct {
+		ReturnCode int    `json:"return_code"`
+		ReturnMsg  string `json:"return_msg"`
+		MsgId      string `json:"msg_id"`
+	}{returnCode, returnMsg, msgId})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal notify response: %w", err)
+	}
+
+	// 待签名内容为 "response_biz_content":{...},"sign_type":"..."
+	signType, err := json.Marshal(c.SignType)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal sign type: %w", err)
+	}
+	content := `"response_biz_content":` + string(bizContent) + `,"sign_type":` + string(signType)
+	content, err = encodeCharset(c.charset(), content)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode notify response: %w", err)
+	}
+	privateKey, err := c.signingKey()
+	if err != nil {
+		return "", err
+	}
+	sign, err := SignWithType(c.SignType, content, privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign notify response: %w", err)
+	}
+	signJson, err := json.Marshal(sign)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal sign: %w", err)
+	}
+	return "{" + content + `,"sign":` + string(signJson) + "}", nil
+}
@@ -1,5 +1,36 @@
 package icbc_api_sdk_go
 
+// 订单查询 pay_status 交易结果标志
+const (
+	PayStatusPaying       = "0" // 支付中，请稍后查询
+	PayStatusSuccess      = "1" // 支付成功
+	PayStatusFailed       = "2" // 支付失败
+	PayStatusRevoked      = "3" // 已撤销
+	PayStatusRevoking     = "4" // 撤销中，请稍后查询
+	PayStatusFullRefunded = "5" // 已全额退款
+	PayStatusPartRefunded = "6" // 已部分退款
+	PayStatusRefunding    = "7" // 退款中，请稍后查询
+)
+
+// IsFinalPayStatus 判断 pay_status 是否为支付终态
+//
+// 支付中、撤销中属于中间状态，需要继续查询；退款相关状态说明订单已支付，视为支付终态
+//
+// 参数:
+//   - payStatus: 订单查询返回的 pay_status
+//
+// 返回值:
+//   - bool: 是否为终态
+func IsFinalPayStatus(payStatus string) bool {
+	switch payStatus {
+	case PayStatusSuccess, PayStatusFailed, PayStatusRevoked,
+		PayStatusFullRefunded, PayStatusPartRefunded, PayStatusRefunding:
+		return true
+	default:
+		return false
+	}
+}
+
 type OrderQueryRequest struct {
 	MerId      string `json:"mer_id,omitempty"`
 	OutTradeNo string `json:"out_trade_no,omitempty"`
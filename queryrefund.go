@@ -1,5 +1,12 @@
 package icbc_api_sdk_go
 
+// 退款查询 pay_status 退款处理结果
+const (
+	RefundStatusSuccess    = "0" // 退款成功
+	RefundStatusFailed     = "1" // 退款失败
+	RefundStatusProcessing = "2" // 退款处理中/结果未知
+)
+
 type QueryRefundRequest struct {
 	MerId          string `json:"mer_id,omitempty"`
 	OutTradeNo     string `json:"out_trade_no,omitempty"`
@@ -7,6 +14,10 @@ type QueryRefundRequest struct {
 	OuttrxSerialNo string `json:"outtrx_serial_no,omitempty"`
 	MerPrtclNo     string `json:"mer_prtcl_no,omitempty"`
 }
+type QueryRefundResp struct {
+	ResponseBizContent QueryRefundResponse `json:"response_biz_content"`
+	Sign               string              `json:"sign"`
+}
 type QueryRefundResponse struct {
 	ReturnCode                  string `json:"return_code"`
 	ReturnMsg                   string `json:"return_msg"`
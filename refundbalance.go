@@ -0,0 +1,195 @@
+package icbc_api_sdk_go
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrRefundExceedsBalance 退款金额超过订单剩余可退金额
+var ErrRefundExceedsBalance = errors.New("refund amount exceeds refundable balance")
+
+// RefundBalance 订单剩余可退金额，单位为分
+type RefundBalance struct {
+	TotalAmt    int64 // 剩余可退总金额，对应 total_amt 减去已退 reject_amt
+	PaymentAmt  int64 // 剩余可退实付金额，对应 payment_amt 减去已退 real_reject_amt
+	PointAmt    int64 // 剩余可退积分抵扣金额，对应 point_amt 减去已退 reject_point
+	EcouponAmt  int64 // 剩余可退电子券金额，对应 ecoupon_amt 减去已退 reject_ecoupon
+	MerDiscAmt  int64 // 剩余可退商户优惠金额，对应 mer_disc_amt 减去已退 reject_mer_disc_amt
+	BankDiscAmt int64 // 剩余可退银行优惠金额，对应 bank_disc_amt 减去已退 reject_bank_disc_amt
+}
+
+// ComputeRefundBalance 根据订单查询结果和历史退款计算剩余可退金额
+//
+// 历史退款中退款失败的记录和查询未成功(return_code 非 0，无法确认退款存在)的记录不计入；
+// 处理中的记录按已退处理，避免并发退款超额。
+//
+// 参数:
+//   - order: 订单查询结果
+//   - priorRefunds: 该订单的历史退款查询结果
+//
+// 返回值:
+//   - *RefundBalance: 剩余可退金额
+//   - error: 错误信息
+func ComputeRefundBalance(order *OrderQueryResp, priorRefunds []QueryRefundResponse) (*RefundBalance, error) {
+	if order == nil {
+		return nil, fmt.Errorf("order cannot be nil")
+	}
+	biz := &order.ResponseBizContent
+	switch biz.PayStatus {
+	case PayStatusSuccess, PayStatusPartRefunded, PayStatusFullRefunded, PayStatusRefunding:
+	default:
+		return nil, fmt.Errorf("order %s is not refundable, pay status %q", orderRef(biz.OrderId, biz.OutTradeNo), biz.PayStatus)
+	}
+
+	balance := &RefundBalance{}
+	fields := []struct {
+		dst   *int64
+		name  string
+		value string
+	}{
+		{&balance.TotalAmt, "total_amt", biz.TotalAmt},
+		{&balance.PaymentAmt, "payment_amt", biz.PaymentAmt},
+		{&balance.PointAmt, "point_amt", biz.PointAmt},
+		{&balance.EcouponAmt, "ecoupon_amt", biz.EcouponAmt},
+		{&balance.MerDiscAmt, "mer_disc_amt", biz.MerDiscAmt},
+		{&balance.BankDiscAmt, "bank_disc_amt", biz.BankDiscAmt},
+	}
+	for _, f := range fields {
+		amt, err := parseAmount(f.name, f.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid order amount: %w", err)
+		}
+		*f.dst = amt
+	}
+
+	for i := range priorRefunds {
+		refund := &priorRefunds[i]
+		if refund.ReturnCode != ReturnCodeSuccess || refund.PayStatus == RefundStatusFailed {
+			continue
+		}
+		if refund.OrderId != "" && biz.OrderId != "" && refund.OrderId != biz.OrderId {
+			return nil, fmt.Errorf("refund %s belongs to order %s, not %s", refund.OuttrxSerialNo, refund.OrderId, biz.OrderId)
+		}
+		rejects := []struct {
+			dst   *int64
+			name  string
+			value string
+		}{
+			{&balance.TotalAmt, "reject_amt", refund.RejectAmt},
+			{&balance.PaymentAmt, "real_reject_amt", refund.RealRejectAmt},
+			{&balance.PointAmt, "reject_point", refund.RejectPoint},
+			{&balance.EcouponAmt, "reject_ecoupon", refund.RejectEcoupon},
+			{&balance.MerDiscAmt, "reject_mer_disc_amt", refund.RejectMerDiscAmt},
+			{&balance.BankDiscAmt, "reject_bank_disc_amt", refund.RejectBankDiscAmt},
+		}
+		for _, r := range rejects {
+			amt, err := parseAmount(r.name, r.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount in refund %s: %w", refund.OuttrxSerialNo, err)
+			}
+			*r.dst -= amt
+			if *r.dst < 0 {
+				return nil, fmt.Errorf("prior refunds exceed order amount: %s is negative after refund %s", r.name, refund.OuttrxSerialNo)
+			}
+		}
+	}
+	return balance, nil
+}
+
+// ValidateRefundRequest 在发送退款请求前校验其是否可以执行
+//
+// 校验内容包括订单号是否匹配、外部退款流水号是否已被使用、退款金额是否超过剩余可退金额。
+// 订单返回了实付金额 payment_amt 时，退款金额还不能超过剩余实付、积分、电子券和优惠金额之和。
+//
+// 参数:
+//   - order: 订单查询结果
+//   - priorRefunds: 该订单的历史退款查询结果
+//   - req: 待发送的退款请求
+//
+// 返回值:
+//   - *RefundBalance: 校验时的剩余可退金额
+//   - error: 校验失败原因，超额时包装 ErrRefundExceedsBalance
+func ValidateRefundRequest(order *OrderQueryResp, priorRefunds []QueryRefundResponse, req *RefundRequest) (*RefundBalance, error) {
+	if req == nil {
+		return nil, fmt.Errorf("refund request cannot be nil")
+	}
+	balance, err := ComputeRefundBalance(order, priorRefunds)
+	if err != nil {
+		return nil, err
+	}
+
+	biz := &order.ResponseBizContent
+	if req.OrderId != "" && biz.OrderId != "" && req.OrderId != biz.OrderId {
+		return balance, fmt.Errorf("refund order_id %s does not match order %s", req.OrderId, biz.OrderId)
+	}
+	if req.OutTradeNo != "" && biz.OutTradeNo != "" && req.OutTradeNo != biz.OutTradeNo {
+		return balance, fmt.Errorf("refund out_trade_no %s does not match order %s", req.OutTradeNo, biz.OutTradeNo)
+	}
+	if req.OrderId == "" && req.OutTradeNo == "" {
+		return balance, fmt.Errorf("refund request must set order_id or out_trade_no")
+	}
+	if req.OuttrxSerialNo != "" {
+		for i := range priorRefunds {
+			if priorRefunds[i].OuttrxSerialNo == req.OuttrxSerialNo {
+				return balance, fmt.Errorf("outtrx_serial_no %s already used by a prior refund", req.OuttrxSerialNo)
+			}
+		}
+	}
+
+	amt, err := parseAmount("ret_total_amt", req.RetTotalAmt)
+	if err != nil {
+		return balance, err
+	}
+	if amt <= 0 {
+		return balance, fmt.Errorf("ret_total_amt must be positive, got %q", req.RetTotalAmt)
+	}
+	if amt > balance.TotalAmt {
+		return balance, fmt.Errorf("%w: ret_total_amt %d, refundable %d", ErrRefundExceedsBalance, amt, balance.TotalAmt)
+	}
+	if strings.TrimSpace(biz.PaymentAmt) != "" {
+		if components := balance.componentsAmt(); amt > components {
+			return balance, fmt.Errorf("%w: ret_total_amt %d, refundable components %d (payment_amt %d, point_amt %d, ecoupon_amt %d, mer_disc_amt %d, bank_disc_amt %d)",
+				ErrRefundExceedsBalance, amt, components, balance.PaymentAmt, balance.PointAmt, balance.EcouponAmt, balance.MerDiscAmt, balance.BankDiscAmt)
+		}
+	}
+	return balance, nil
+}
+
+// componentsAmt 返回剩余可退的实付、积分、电子券和优惠金额之和
+func (b *RefundBalance) componentsAmt() int64 {
+	return b.PaymentAmt + b.PointAmt + b.EcouponAmt + b.MerDiscAmt + b.BankDiscAmt
+}
+
+// parseAmount 解析以分为单位的金额字符串，空字符串视为 0
+//
+// 参数:
+//   - name: 字段名，用于错误信息
+//   - value: 金额字符串
+//
+// 返回值:
+//   - int64: 金额(分)
+//   - error: 错误信息
+func parseAmount(name, value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	amt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q is not an amount in cents: %w", name, value, err)
+	}
+	if amt < 0 {
+		return 0, fmt.Errorf("%s %q cannot be negative", name, value)
+	}
+	return amt, nil
+}
+
+// orderRef 返回用于错误信息的订单标识
+func orderRef(orderId, outTradeNo string) string {
+	if orderId != "" {
+		return orderId
+	}
+	return outTradeNo
+}
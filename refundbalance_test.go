@@ -0,0 +1,103 @@
+package icbc_api_sdk_go_test
+
+import (
+	"errors"
+	"testing"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+)
+
+// newPaidOrder 创建订单金额 1000 分、实付 700 分、积分 100 分、商户优惠 200 分的订单查询结果
+func newPaidOrder() *icbc.OrderQueryResp {
+	order := &icbc.OrderQueryResp{}
+	biz := &order.ResponseBizContent
+	biz.ReturnCode = icbc.ReturnCodeSuccess
+	biz.PayStatus = icbc.PayStatusSuccess
+	biz.OrderId = "ORD1"
+	biz.OutTradeNo = "T1"
+	biz.TotalAmt = "1000"
+	biz.PaymentAmt = "700"
+	biz.PointAmt = "100"
+	biz.MerDiscAmt = "200"
+	return order
+}
+
+func TestComputeRefundBalance(t *testing.T) {
+	refunded := icbc.QueryRefundResponse{
+		ReturnCode: icbc.ReturnCodeSuccess, PayStatus: icbc.RefundStatusSuccess, OuttrxSerialNo: "R1",
+		RejectAmt: "300", RealRejectAmt: "200", RejectPoint: "100",
+	}
+	tests := []struct {
+		name  string
+		prior []icbc.QueryRefundResponse
+		want  icbc.RefundBalance
+	}{
+		{"no refunds", nil, icbc.RefundBalance{TotalAmt: 1000, PaymentAmt: 700, PointAmt: 100, MerDiscAmt: 200}},
+		{"refunded", []icbc.QueryRefundResponse{refunded}, icbc.RefundBalance{TotalAmt: 700, PaymentAmt: 500, MerDiscAmt: 200}},
+		{"processing counts as refunded", []icbc.QueryRefundResponse{withStatus(refunded, icbc.ReturnCodeSuccess, icbc.RefundStatusProcessing)},
+			icbc.RefundBalance{TotalAmt: 700, PaymentAmt: 500, MerDiscAmt: 200}},
+		{"failed refund skipped", []icbc.QueryRefundResponse{withStatus(refunded, icbc.ReturnCodeSuccess, icbc.RefundStatusFailed)},
+			icbc.RefundBalance{TotalAmt: 1000, PaymentAmt: 700, PointAmt: 100, MerDiscAmt: 200}},
+		{"unconfirmed refund skipped", []icbc.QueryRefundResponse{withStatus(refunded, "10001", "")},
+			icbc.RefundBalance{TotalAmt: 1000, PaymentAmt: 700, PointAmt: 100, MerDiscAmt: 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := icbc.ComputeRefundBalance(newPaidOrder(), tt.prior)
+			if err != nil {
+				t.Fatalf("ComputeRefundBalance: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("balance = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRefundRequest(t *testing.T) {
+	// 已退 300 分：实付 200 分、积分 100 分，剩余总额 700 分
+	refunded := []icbc.QueryRefundResponse{{
+		ReturnCode: icbc.ReturnCodeSuccess, PayStatus: icbc.RefundStatusSuccess, OuttrxSerialNo: "R1",
+		RejectAmt: "300", RealRejectAmt: "200", RejectPoint: "100",
+	}}
+	tests := []struct {
+		name       string
+		order      func(o *icbc.OrderQueryResp)
+		prior      []icbc.QueryRefundResponse
+		req        icbc.RefundRequest
+		wantErr    bool
+		wantExceed bool // 是否为超额错误
+	}{
+		{"within balance", nil, refunded, icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R2", RetTotalAmt: "700"}, false, false},
+		{"exceeds total", nil, refunded, icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R2", RetTotalAmt: "701"}, true, true},
+		{"exceeds components", func(o *icbc.OrderQueryResp) { o.ResponseBizContent.MerDiscAmt = "0" },
+			refunded, icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R2", RetTotalAmt: "600"}, true, true},
+		{"no component breakdown", func(o *icbc.OrderQueryResp) {
+			o.ResponseBizContent.PaymentAmt, o.ResponseBizContent.PointAmt, o.ResponseBizContent.MerDiscAmt = "", "", ""
+		}, nil, icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R2", RetTotalAmt: "1000"}, false, false},
+		{"serial no reused", nil, refunded, icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R1", RetTotalAmt: "1"}, true, false},
+		{"order mismatch", nil, refunded, icbc.RefundRequest{OutTradeNo: "T2", OuttrxSerialNo: "R2", RetTotalAmt: "1"}, true, false},
+		{"zero amount", nil, refunded, icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R2", RetTotalAmt: "0"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := newPaidOrder()
+			if tt.order != nil {
+				tt.order(order)
+			}
+			_, err := icbc.ValidateRefundRequest(order, tt.prior, &tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateRefundRequest error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, icbc.ErrRefundExceedsBalance); got != tt.wantExceed {
+				t.Errorf("errors.Is(%v, ErrRefundExceedsBalance) = %v, want %v", err, got, tt.wantExceed)
+			}
+		})
+	}
+}
+
+// withStatus 返回修改了返回码和退款状态的退款查询结果
+func withStatus(r icbc.QueryRefundResponse, returnCode, payStatus string) icbc.QueryRefundResponse {
+	r.ReturnCode, r.PayStatus = returnCode, payStatus
+	return r
+}
@@ -0,0 +1,199 @@
+package icbc_api_sdk_go
+
+import (
+	"context"
+	"fmt"
+)
+
+// RefundOutcome 退款最终结果
+type RefundOutcome string
+
+const (
+	RefundOutcomeSuccess RefundOutcome = "SUCCESS" // 退款成功
+	RefundOutcomeFailed  RefundOutcome = "FAILED"  // 退款失败，资金未退回
+	RefundOutcomeUnknown RefundOutcome = "UNKNOWN" // 多次查询后仍无法确定结果，需人工核实
+)
+
+// DefaultRefundMaxQueries 退款结果不确定时默认的最大补偿查询次数
+const DefaultRefundMaxQueries = 10
+
+// RefundOptions 退款流程配置
+type RefundOptions struct {
+	QueryBackoff Backoff // 补偿查询的退避配置，零值时使用 DefaultBackoff
+	MaxQueries   int     // 最大补偿查询次数，0 时使用 DefaultRefundMaxQueries
+	// IsAmbiguous 判断退款接口返回码是否代表结果不确定，需要补偿查询
+	// 为 nil 时所有非成功返回码都视为不确定
+	IsAmbiguous func(returnCode string) bool
+}
+
+// RefundResult 退款流程结果
+type RefundResult struct {
+	Outcome        RefundOutcome    // 最终结果
+	OuttrxSerialNo string           // 本次退款使用的外部退款流水号
+	Refund         *RefundResp      // 退款接口响应，网络异常或验签失败时为 nil
+	Query          *QueryRefundResp // 最后一次成功的补偿查询结果，未查询时为 nil
+	RefundCall     *CallResult      // 退款请求的调用信息，包含 msg_id，可凭此向工行核实
+	QueryCall      *CallResult      // 最后一次补偿查询的调用信息，未查询时为 nil
+}
+
+// NewOuttrxSerialNo 生成外部退款流水号
+//
+// 调用方应在发起退款前持久化该流水号，重试时复用同一个流水号。
+//
+// 返回值:
+//   - string: 去掉连字符的 UUID V7 字符串
+//   - error: 错误信息
+func NewOuttrxSerialNo() (string, error) {
+	serialNo, err := newUUIDString()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate outtrx serial no: %w", err)
+	}
+	return serialNo, nil
+}
+
+// RefundAndConfirm 发起退款并确认最终结果
+//
+// 退款请求只会发送一次。网络异常、验签失败或返回码不确定时，使用同一个 OuttrxSerialNo
+// 调用退款查询接口，直到得到明确的成功或失败结果，绝不会换新的流水号重复退款。
+// req.OuttrxSerialNo 不能为空：调用方应先用 NewOuttrxSerialNo 生成并持久化流水号，再调用本方法，
+// 进程崩溃后重试时使用已持久化的流水号，避免重复退款。
+//
+// 参数:
+//   - ctx: 上下文，同时控制退款请求与补偿查询
+//   - refundUrl: 退款接口地址，为空时使用 ServiceURL(PathRefund)
+//   - queryUrl: 退款查询接口地址，为空时使用 ServiceURL(PathRefundQuery)
+//   - req: 退款请求
+//   - opts: 流程配置，可为 nil
+//
+// 返回值:
+//   - *RefundResult: 退款结果，结果为 RefundOutcomeUnknown 时同时返回 error
+//   - error: 错误信息，req.OuttrxSerialNo 为空时直接返回错误，不发起退款
+func (c *DefaultClient) RefundAndConfirm(ctx context.Context, refundUrl, queryUrl string, req *RefundRequest, opts *RefundOptions) (*RefundResult, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context cannot be nil")
+	}
+	if req == nil {
+		return nil, fmt.Errorf("refund request cannot be nil")
+	}
+	if req.OuttrxSerialNo == "" {
+		return nil, fmt.Errorf("outtrx serial no cannot be empty, generate it with NewOuttrxSerialNo and persist it before refunding")
+	}
+	refundUrl, err := c.serviceURLOrDefault(refundUrl, PathRefund)
+	if err != nil {
+		return nil, err
+	}
+	queryUrl, err = c.serviceURLOrDefault(queryUrl, PathRefundQuery)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &RefundOptions{}
+	}
+	result := &RefundResult{
+		Outcome:        RefundOutcomeUnknown,
+		OuttrxSerialNo: req.OuttrxSerialNo,
+	}
+
+	refundResp := &RefundResp{}
+	refundCall, refundErr := c.ExecuteCall(ctx, &ICBCRequest{
+		ServiceUrl: refundUrl,
+		BizContent: req,
+		Method:     "POST",
+	}, "", refundResp)
+	result.RefundCall = refundCall
+	if refundErr == nil {
+		result.Refund = refundResp
+		returnCode := refundResp.ResponseBizContent.ReturnCode
+		if returnCode == ReturnCodeSuccess {
+			result.Outcome = RefundOutcomeSuccess
+			return result, nil
+		}
+		if opts.IsAmbiguous != nil && !opts.IsAmbiguous(returnCode) {
+			result.Outcome = RefundOutcomeFailed
+			return result, nil
+		}
+	}
+
+	queryErr := c.confirmRefund(ctx, queryUrl, req, opts, result)
+	if result.Outcome != RefundOutcomeUnknown {
+		return result, nil
+	}
+	if refundErr != nil {
+		return result, fmt.Errorf("refund %s outcome unknown: %w, refund error: %w", req.OuttrxSerialNo, queryErr, refundErr)
+	}
+	return result, fmt.Errorf("refund %s outcome unknown: %w", req.OuttrxSerialNo, queryErr)
+}
+
+// confirmRefund 按外部退款流水号轮询退款查询接口，直到得到明确结果
+//
+// 参数:
+//   - ctx: 上下文
+//   - queryUrl: 退款查询接口地址
+//   - req: 原退款请求
+//   - opts: 流程配置
+//   - result: 退款结果，查询到明确结果时更新 Outcome
+//
+// 返回值:
+//   - error: 未得到明确结果的原因
+func (c *DefaultClient) confirmRefund(ctx context.Context, queryUrl string, req *RefundRequest, opts *RefundOptions, result *RefundResult) error {
+	backoff := opts.QueryBackoff
+	if backoff == (Backoff{}) {
+		backoff = DefaultBackoff
+	}
+	maxQueries := opts.MaxQueries
+	if maxQueries <= 0 {
+		maxQueries = DefaultRefundMaxQueries
+	}
+
+	request := &ICBCRequest{
+		ServiceUrl: queryUrl,
+		BizContent: &QueryRefundRequest{
+			MerId:          req.MerId,
+			OutTradeNo:     req.OutTradeNo,
+			OrderId:        req.OrderId,
+			OuttrxSerialNo: req.OuttrxSerialNo,
+			MerPrtclNo:     req.MerPrtclNo,
+		},
+		Method:     "POST",
+		Idempotent: true,
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxQueries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, backoff.Delay(attempt-1)); err != nil {
+				if lastErr != nil {
+					return fmt.Errorf("%w, last query error: %w", err, lastErr)
+				}
+				return err
+			}
+		}
+
+		queryResp := &QueryRefundResp{}
+		queryCall, err := c.ExecuteCall(ctx, request, "", queryResp)
+		if queryCall != nil {
+			result.QueryCall = queryCall
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		result.Query = queryResp
+		biz := queryResp.ResponseBizContent
+		if biz.ReturnCode != ReturnCodeSuccess {
+			lastErr = fmt.Errorf("query refund return code %s: %s", biz.ReturnCode, biz.ReturnMsg)
+			continue
+		}
+		switch biz.PayStatus {
+		case RefundStatusSuccess:
+			result.Outcome = RefundOutcomeSuccess
+			return nil
+		case RefundStatusFailed:
+			result.Outcome = RefundOutcomeFailed
+			return nil
+		default:
+			lastErr = fmt.Errorf("refund still processing, pay status %s", biz.PayStatus)
+		}
+	}
+	return fmt.Errorf("max queries %d reached: %w", maxQueries, lastErr)
+}
@@ -0,0 +1,136 @@
+package icbc_api_sdk_go_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+)
+
+// refundQueries 返回网关收到的退款查询次数
+func refundQueries(g *icbctest.Gateway) int {
+	n := 0
+	for _, req := range g.Requests() {
+		if req.Path == icbc.PathRefundQuery {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRefundAndConfirm(t *testing.T) {
+	tests := []struct {
+		name        string
+		serialNo    string
+		amount      string
+		fault       *icbctest.Fault
+		isAmbiguous func(returnCode string) bool
+		wantErr     bool
+		wantOutcome icbc.RefundOutcome
+		wantQueries int
+	}{
+		{
+			name:        "success",
+			wantOutcome: icbc.RefundOutcomeSuccess,
+		},
+		{
+			name:        "missing serial no",
+			serialNo:    "-",
+			wantErr:     true,
+			wantQueries: 0,
+		},
+		{
+			name:        "response lost after refund",
+			fault:       &icbctest.Fault{Path: icbc.PathRefund, Times: 1, AfterHandler: true, HTTPStatus: http.StatusBadGateway},
+			wantOutcome: icbc.RefundOutcomeSuccess,
+			wantQueries: 1,
+		},
+		{
+			name:        "refund never reached the bank",
+			fault:       &icbctest.Fault{Path: icbc.PathRefund, Times: 1, HTTPStatus: http.StatusBadGateway},
+			wantErr:     true,
+			wantOutcome: icbc.RefundOutcomeUnknown,
+			wantQueries: 3,
+		},
+		{
+			name:        "definite failure",
+			amount:      "1000",
+			isAmbiguous: func(returnCode string) bool { return returnCode != icbctest.ReturnCodeInvalidAmount },
+			wantOutcome: icbc.RefundOutcomeFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway, client := newGatewayClient(t)
+			gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100, PayStatus: icbc.PayStatusSuccess})
+			if tt.fault != nil {
+				gateway.InjectFault(*tt.fault)
+			}
+			req := &icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R1", RetTotalAmt: "40"}
+			if tt.serialNo == "-" {
+				req.OuttrxSerialNo = ""
+			}
+			if tt.amount != "" {
+				req.RetTotalAmt = tt.amount
+			}
+
+			result, err := client.RefundAndConfirm(context.Background(), "", "", req,
+				&icbc.RefundOptions{QueryBackoff: fastBackoff, MaxQueries: 3, IsAmbiguous: tt.isAmbiguous})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RefundAndConfirm error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != nil && result.Outcome != tt.wantOutcome {
+				t.Errorf("outcome = %s, want %s", result.Outcome, tt.wantOutcome)
+			}
+			if n := refundQueries(gateway); n != tt.wantQueries {
+				t.Errorf("gateway received %d refund queries, want %d", n, tt.wantQueries)
+			}
+			// 退款请求最多发送一次，结果不确定时只做补偿查询
+			refunds := len(gateway.Requests()) - refundQueries(gateway)
+			if wantRefunds := min(1, len(req.OuttrxSerialNo)); refunds != wantRefunds {
+				t.Errorf("gateway received %d refund requests, want %d", refunds, wantRefunds)
+			}
+		})
+	}
+}
+
+func TestRefundAndConfirmAsyncRefund(t *testing.T) {
+	gateway, client := newGatewayClient(t)
+	gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100, PayStatus: icbc.PayStatusSuccess})
+	gateway.NewRefundStatus = icbc.RefundStatusProcessing
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// 收到第一次退款查询后模拟工行异步完成退款
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for refundQueries(gateway) == 0 {
+			if ctx.Err() != nil {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		_ = gateway.SetRefundStatus("T1", "R1", icbc.RefundStatusSuccess)
+	}()
+
+	result, err := client.RefundAndConfirm(ctx, "", "", &icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R1", RetTotalAmt: "40"},
+		&icbc.RefundOptions{QueryBackoff: fastBackoff, MaxQueries: 1000})
+	cancel()
+	<-done
+	if err != nil {
+		t.Fatalf("RefundAndConfirm: %v", err)
+	}
+	if result.Outcome != icbc.RefundOutcomeSuccess {
+		t.Errorf("outcome = %s, want %s", result.Outcome, icbc.RefundOutcomeSuccess)
+	}
+	if result.Refund == nil || result.Refund.ResponseBizContent.ReturnCode != icbctest.ReturnCodeRefunding {
+		t.Errorf("refund response = %+v, want return_code %s", result.Refund, icbctest.ReturnCodeRefunding)
+	}
+	if result.Query == nil || result.Query.ResponseBizContent.PayStatus != icbc.RefundStatusSuccess {
+		t.Errorf("query response = %+v, want pay_status %s", result.Query, icbc.RefundStatusSuccess)
+	}
+}
@@ -0,0 +1,195 @@
+package icbc_api_sdk_go
+
+import (
+	"fmt"
+	"net/http"
+	URL "net/url"
+	"sort"
+	"sync"
+)
+
+// Registry 多商户、多应用的客户端注册表
+//
+// 每个应用(app_id)对应一个客户端，使用各自的密钥和签名类型，可关联多个商户编号(mer_id)。
+// 注册表中的客户端共享同一个HTTP客户端及其连接池。
+type Registry struct {
+	mu         sync.RWMutex
+	httpClient *http.Client
+	apps       map[string]*DefaultClient
+	merchants  map[string]*DefaultClient
+}
+
+// NewRegistry 创建客户端注册表
+//
+// 参数:
+//   - httpClient: 所有客户端共享的HTTP客户端，为 nil 时使用默认客户端
+//
+// 返回值:
+//   - *Registry: 注册表
+func NewRegistry(httpClient *http.Client) *Registry {
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	return &Registry{
+		httpClient: httpClient,
+		apps:       make(map[string]*DefaultClient),
+		merchants:  make(map[string]*DefaultClient),
+	}
+}
+
+// Register 注册客户端，未配置 HTTPClient 的客户端会使用注册表共享的HTTP客户端
+//
+// 参数:
+//   - client: 客户端，APPID 不能为空
+//   - merIDs: 客户端对应的商户编号
+//
+// 返回值:
+//   - error: app_id 或 mer_id 已注册时返回错误
+func (r *Registry) Register(client *DefaultClient, merIDs ...string) error {
+	if client == nil {
+		return fmt.Errorf("client cannot be nil")
+	}
+	if client.APPID == "" {
+		return fmt.Errorf("client app id cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.apps[client.APPID]; ok {
+		return fmt.Errorf("app %s is already registered", client.APPID)
+	}
+	for _, merID := range merIDs {
+		if existing, ok := r.merchants[merID]; ok {
+			return fmt.Errorf("mer_id %s is already registered to app %s", merID, existing.APPID)
+		}
+	}
+	if client.HTTPClient == nil {
+		client.HTTPClient = r.httpClient
+	}
+	r.apps[client.APPID] = client
+	for _, merID := range merIDs {
+		r.merchants[merID] = client
+	}
+	return nil
+}
+
+// RegisterConfig 按配置创建客户端并注册，商户编号取自 Config.MerIDs
+//
+// 配置中的超时和代理不生效，客户端统一使用注册表共享的HTTP客户端。
+//
+// 参数:
+//   - cfg: 应用配置
+//
+// 返回值:
+//   - *DefaultClient: 注册的客户端
+//   - error: 错误信息
+func (r *Registry) RegisterConfig(cfg *Config) (*DefaultClient, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	client, err := cfg.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("app %s: %w", cfg.AppID, err)
+	}
+	client.HTTPClient = r.httpClient
+	if err := r.Register(client, cfg.MerIDs...); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Client 按应用ID查找客户端
+//
+// 参数:
+//   - appID: 应用ID
+//
+// 返回值:
+//   - *DefaultClient: 客户端
+//   - error: 未注册时返回 ErrUnknownApp
+func (r *Registry) Client(appID string) (*DefaultClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.apps[appID]
+	if !ok {
+		return nil, fmt.Errorf("%w: app_id %s", ErrUnknownApp, appID)
+	}
+	return client, nil
+}
+
+// ClientByMerID 按商户编号查找客户端
+//
+// 参数:
+//   - merID: 商户编号
+//
+// 返回值:
+//   - *DefaultClient: 客户端
+//   - error: 未注册时返回 ErrUnknownApp
+func (r *Registry) ClientByMerID(merID string) (*DefaultClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.merchants[merID]
+	if !ok {
+		return nil, fmt.Errorf("%w: mer_id %s", ErrUnknownApp, merID)
+	}
+	return client, nil
+}
+
+// AppIDs 返回已注册的应用ID，按字典序排序
+//
+// 返回值:
+//   - []string: 应用ID
+func (r *Registry) AppIDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	appIDs := make([]string, 0, len(r.apps))
+	for appID := range r.apps {
+		appIDs = append(appIDs, appID)
+	}
+	sort.Strings(appIDs)
+	return appIDs
+}
+
+// ParseNotify 按通知中的 app_id 选择客户端，解析并验签工行异步通知请求
+//
+// 参数:
+//   - req: 工行回调商户 notify_url 的HTTP请求
+//
+// 返回值:
+//   - *Notify: 通知内容
+//   - *DefaultClient: 通知所属应用的客户端，可用于 BuildNotifyResponse
+//   - error: 错误信息，app_id 未注册时返回 ErrUnknownApp
+func (r *Registry) ParseNotify(req *http.Request) (*Notify, *DefaultClient, error) {
+	if req == nil {
+		return nil, nil, fmt.Errorf("request cannot be nil")
+	}
+	if err := req.ParseForm(); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse notify form: %w", err)
+	}
+	return r.VerifyNotify(req.URL.Path, req.PostForm)
+}
+
+// VerifyNotify 按通知中的 app_id 选择客户端，验签工行异步通知参数并解析 biz_content
+//
+// 参数:
+//   - path: notify_url 的路径
+//   - form: 通知的表单参数
+//
+// 返回值:
+//   - *Notify: 通知内容
+//   - *DefaultClient: 通知所属应用的客户端
+//   - error: 错误信息，app_id 未注册时返回 ErrUnknownApp
+func (r *Registry) VerifyNotify(path string, form URL.Values) (*Notify, *DefaultClient, error) {
+	appID := form.Get("app_id")
+	if appID == "" {
+		return nil, nil, fmt.Errorf("notify app_id cannot be empty")
+	}
+	client, err := r.Client(appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	notify, err := client.VerifyNotify(path, form)
+	if err != nil {
+		return nil, client, err
+	}
+	return notify, client, nil
+}
@@ -0,0 +1,303 @@
+package icbc_api_sdk_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// DefaultMaxResponseSize 响应体的默认最大字节数
+const DefaultMaxResponseSize = 10 << 20
+
+// readResponseBody 读取响应体，最多读取 limit 字节
+//
+// 参数:
+//   - r: 响应体
+//   - contentLength: 响应头中的长度，未知时为 -1，已知时用于预分配缓冲区
+//   - limit: 最大字节数
+//
+// 返回值:
+//   - []byte: 响应体
+//   - error: 错误信息，超过 limit 时返回包装 ErrResponseTooLarge 的错误
+func readResponseBody(r io.Reader, contentLength, limit int64) ([]byte, error) {
+	if contentLength > limit {
+		return nil, fmt.Errorf("%w: content length %d exceeds limit of %d bytes", ErrResponseTooLarge, contentLength, limit)
+	}
+	var buf bytes.Buffer
+	if contentLength > 0 {
+		// 多预留 MinRead 字节，读到 EOF 时无需再次扩容
+		buf.Grow(int(contentLength) + bytes.MinRead)
+	}
+	n, err := buf.ReadFrom(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if n > limit {
+		return nil, fmt.Errorf("%w: exceeds limit of %d bytes", ErrResponseTooLarge, limit)
+	}
+	return buf.Bytes(), nil
+}
+
+// responseEnvelope 响应体中验签所需的字段
+type responseEnvelope struct {
+	bizContent []byte // response_biz_content 的原文，引用响应体中的字节
+	sign       string
+}
+
+// scanResponse 单次扫描响应体的顶层对象，定位 response_biz_content 原文并解析 sign
+//
+// 只校验顶层对象和嵌套括号、字符串的结构，不复制业务内容；完整的JSON校验由随后对目标对象的解析完成。
+// 扫描在客户端字符集的原始字节上进行，GBK 双字节字符的第二个字节可能是 '\\' 或括号，字符串中按双字节跳过。
+// encoding/json 按大小写不敏感匹配字段，顶层键忽略大小写后与 response_biz_content 或 sign 重复时返回错误，
+// 避免未签名的同名字段被解析到目标对象。
+//
+// 参数:
+//   - body: 原始响应体 {"response_biz_content":{...},"sign":"..."}
+//   - charset: 响应体的字符集
+//
+// 返回值:
+//   - responseEnvelope: 验签所需的字段
+//   - error: 响应体不是JSON对象或包含重复的 response_biz_content、sign 时返回错误
+func scanResponse(body []byte, charset string) (responseEnvelope, error) {
+	gbk := isGBK(charset)
+	var env responseEnvelope
+	var seenBizContent, seenSign bool
+	i := skipSpace(body, 0)
+	if i >= len(body) || body[i] != '{' {
+		return env, invalidResponseError(i, "expected object")
+	}
+	i = skipSpace(body, i+1)
+	if i < len(body) && body[i] == '}' {
+		i++
+	} else {
+		for {
+			keyEnd, err := scanString(body, i, gbk)
+			if err != nil {
+				return env, err
+			}
+			keyStart, key := i, body[i+1:keyEnd-1]
+			if bytes.IndexByte(key, '\\') >= 0 {
+				// 含转义的键先还原，"response\u005fbiz_content" 与 response_biz_content 是同一个键
+				var unquoted string
+				if err := json.Unmarshal(body[i:keyEnd], &unquoted); err != nil {
+					return env, invalidResponseError(i, "invalid key")
+				}
+				key = []byte(unquoted)
+			}
+			i = skipSpace(body, keyEnd)
+			if i >= len(body) || body[i] != ':' {
+				return env, invalidResponseError(i, "expected ':'")
+			}
+			i = skipSpace(body, i+1)
+			valueEnd, err := scanValue(body, i, gbk)
+			if err != nil {
+				return env, err
+			}
+			switch {
+			case bytes.EqualFold(key, []byte("response_biz_content")):
+				if seenBizContent {
+					return env, invalidResponseError(keyStart, "duplicate key response_biz_content")
+				}
+				seenBizContent = true
+				if string(key) == "response_biz_content" {
+					env.bizContent = body[i:valueEnd]
+				}
+			case bytes.EqualFold(key, []byte("sign")):
+				if seenSign {
+					return env, invalidResponseError(keyStart, "duplicate key sign")
+				}
+				seenSign = true
+				if string(key) == "sign" {
+					if err := json.Unmarshal(body[i:valueEnd], &env.sign); err != nil {
+						return env, fmt.Errorf("invalid response sign: %w", err)
+					}
+				}
+			}
+			i = skipSpace(body, valueEnd)
+			if i < len(body) && body[i] == ',' {
+				i = skipSpace(body, i+1)
+				continue
+			}
+			if i < len(body) && body[i] == '}' {
+				i++
+				break
+			}
+			return env, invalidResponseError(i, "expected ',' or '}'")
+		}
+	}
+	if i = skipSpace(body, i); i != len(body) {
+		return env, invalidResponseError(i, "unexpected data after object")
+	}
+	return env, nil
+}
+
+// decodeResponse 将验签通过的 response_biz_content 和 sign 解析到目标对象
+//
+// 目标对象只从验签通过的原文解析，响应体中的其它字段不会写入目标对象。
+// 目标为带 response_biz_content 字段的结构体指针时直接解析该字段，不再扫描整个响应体；
+// 其它目标(如 map、嵌入结构体)重新组装 {"response_biz_content":...,"sign":"..."} 后解析。
+//
+// 参数:
+//   - bizContent: 验签通过的 response_biz_content 原文
+//   - sign: 响应签名
+//   - res: 响应对象指针
+//
+// 返回值:
+//   - error: 错误信息
+func decodeResponse(bizContent json.RawMessage, sign string, res any) error {
+	v := reflect.ValueOf(res)
+	if v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+		if bizField, signField, ok := responseFields(v.Elem().Type()); ok {
+			if err := json.Unmarshal(bizContent, v.Elem().Field(bizField).Addr().Interface()); err != nil {
+				return err
+			}
+			if signField >= 0 {
+				v.Elem().Field(signField).SetString(sign)
+			}
+			return nil
+		}
+	}
+
+	signJson, err := json.Marshal(sign)
+	if err != nil {
+		return err
+	}
+	envelope := make([]byte, 0, len(bizContent)+len(signJson)+32)
+	envelope = append(envelope, `{"response_biz_content":`...)
+	envelope = append(envelope, bizContent...)
+	envelope = append(envelope, `,"sign":`...)
+	envelope = append(envelope, signJson...)
+	envelope = append(envelope, '}')
+	return json.Unmarshal(envelope, res)
+}
+
+// responseFields 查找结构体中接收 response_biz_content 和 sign 的字段下标
+//
+// 只处理字段名唯一且明确的情况：存在嵌入字段、大小写不同的同名字段或 sign 不是字符串时返回 false，
+// 由调用方按 encoding/json 的规则解析。
+//
+// 返回值:
+//   - bizContent: response_biz_content 字段下标
+//   - sign: sign 字段下标，没有时为 -1
+//   - ok: 是否可以直接解析
+func responseFields(t reflect.Type) (bizContent, sign int, ok bool) {
+	bizContent, sign = -1, -1
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			return -1, -1, false
+		}
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		switch {
+		case strings.EqualFold(name, "response_biz_content"):
+			if name != "response_biz_content" || bizContent >= 0 {
+				return -1, -1, false
+			}
+			bizContent = i
+		case strings.EqualFold(name, "sign"):
+			if name != "sign" || sign >= 0 || field.Type.Kind() != reflect.String {
+				return -1, -1, false
+			}
+			sign = i
+		}
+	}
+	return bizContent, sign, bizContent >= 0
+}
+
+// scanValue 返回从 i 开始的JSON值的结束位置，gbk 为 true 时字符串按 GBK 双字节扫描
+func scanValue(b []byte, i int, gbk bool) (int, error) {
+	if i >= len(b) {
+		return i, invalidResponseError(i, "unexpected end of input")
+	}
+	switch b[i] {
+	case '"':
+		return scanString(b, i, gbk)
+	case '{', '[':
+		// 记录未闭合的括号，字符串中的括号不计入
+		stack := []byte{b[i]}
+		for j := i + 1; j < len(b); j++ {
+			switch c := b[j]; c {
+			case '"':
+				end, err := scanString(b, j, gbk)
+				if err != nil {
+					return end, err
+				}
+				j = end - 1
+			case '{', '[':
+				stack = append(stack, c)
+			case '}', ']':
+				open := byte('{')
+				if c == ']' {
+					open = '['
+				}
+				if stack[len(stack)-1] != open {
+					return j, invalidResponseError(j, "mismatched bracket")
+				}
+				stack = stack[:len(stack)-1]
+				if len(stack) == 0 {
+					return j + 1, nil
+				}
+			}
+		}
+		return len(b), invalidResponseError(len(b), "unexpected end of input")
+	default:
+		// 数字、true、false、null
+		j := i
+		for j < len(b) && !isSpace(b[j]) && b[j] != ',' && b[j] != '}' && b[j] != ']' {
+			j++
+		}
+		if !json.Valid(b[i:j]) {
+			return j, invalidResponseError(i, "invalid literal")
+		}
+		return j, nil
+	}
+}
+
+// scanString 返回从 i 开始的JSON字符串的结束位置(结束引号之后)，gbk 为 true 时跳过 GBK 双字节字符的第二个字节
+func scanString(b []byte, i int, gbk bool) (int, error) {
+	if i >= len(b) || b[i] != '"' {
+		return i, invalidResponseError(i, "expected string")
+	}
+	for j := i + 1; j < len(b); j++ {
+		switch c := b[j]; {
+		case c == '\\', gbk && c >= 0x81:
+			j++
+		case c == '"':
+			return j + 1, nil
+		case c < 0x20:
+			return j, invalidResponseError(j, "control character in string")
+		}
+	}
+	return len(b), invalidResponseError(len(b), "unterminated string")
+}
+
+// skipSpace 跳过JSON空白字符
+func skipSpace(b []byte, i int) int {
+	for i < len(b) && isSpace(b[i]) {
+		i++
+	}
+	return i
+}
+
+// isSpace 判断是否为JSON空白字符
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// invalidResponseError 响应体格式错误
+func invalidResponseError(offset int, reason string) error {
+	return fmt.Errorf("invalid response json body at offset %d: %s", offset, reason)
+}
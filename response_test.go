@@ -0,0 +1,137 @@
+package icbc_api_sdk_go_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+)
+
+// roundTripFunc 用函数实现 http.RoundTripper
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newStaticClient 创建对每个请求都返回 body 的客户端，返回值中的密钥用于签名响应
+func newStaticClient(tb testing.TB, body func() []byte) (*icbc.DefaultClient, string) {
+	tb.Helper()
+	merchantKey, _, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		tb.Fatalf("GenerateKeyPair: %v", err)
+	}
+	gatewayKey, gatewayPub, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		tb.Fatalf("GenerateKeyPair: %v", err)
+	}
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		b := body()
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Header:        http.Header{"Content-Type": []string{"application/json; charset=UTF-8"}},
+			Body:          io.NopCloser(bytes.NewReader(b)),
+			ContentLength: int64(len(b)),
+			Request:       req,
+		}, nil
+	})
+	return &icbc.DefaultClient{
+		APPID:         "10000000000000000001",
+		PrivateKey:    merchantKey,
+		SignType:      "RSA2",
+		IcbcPublicKey: gatewayPub,
+		HTTPClient:    &http.Client{Transport: transport},
+	}, gatewayKey
+}
+
+// signedResponse 使用网关私钥签名 response_biz_content 并拼接响应体
+func signedResponse(tb testing.TB, gatewayKey, bizContent string, extra string) []byte {
+	tb.Helper()
+	sign, err := icbc.SignWithSHA1RSA(bizContent, gatewayKey)
+	if err != nil {
+		tb.Fatalf("SignWithSHA1RSA: %v", err)
+	}
+	return []byte(`{"response_biz_content":` + bizContent + extra + `,"sign":"` + sign + `"}`)
+}
+
+func TestExecuteRejectsUnsignedBizContent(t *testing.T) {
+	signed := `{"return_code":"0","pay_status":"2","total_amt":"1"}`
+	forged := `{"return_code":"0","pay_status":"1","total_amt":"999999"}`
+	for _, extra := range []string{
+		`,"Response_Biz_Content":` + forged,
+		`,"RESPONSE_BIZ_CONTENT":` + forged,
+		`,"response_biz_content":` + forged,
+		`,"response\u005fbiz_content":` + forged,
+	} {
+		var body []byte
+		client, gatewayKey := newStaticClient(t, func() []byte { return body })
+		body = signedResponse(t, gatewayKey, signed, extra)
+
+		resp := &icbc.OrderQueryResp{}
+		_, err := client.ExecuteWithContext(context.Background(), &icbc.ICBCRequest{ServiceUrl: "https://gw.example.com" + icbc.PathOrderQuery}, "", resp)
+		if err == nil {
+			t.Errorf("response with extra %s accepted, pay_status=%s total_amt=%s", extra, resp.ResponseBizContent.PayStatus, resp.ResponseBizContent.TotalAmt)
+		}
+		if resp.ResponseBizContent.TotalAmt == "999999" {
+			t.Errorf("response with extra %s decoded unsigned content", extra)
+		}
+	}
+}
+
+func TestExecuteDecodesVerifiedBizContent(t *testing.T) {
+	var body []byte
+	client, gatewayKey := newStaticClient(t, func() []byte { return body })
+	body = signedResponse(t, gatewayKey, `{"return_code":"0","pay_status":"1","total_amt":"100"}`, "")
+
+	for _, res := range []any{&icbc.OrderQueryResp{}, &icbc.IcbcResponse{}, &map[string]any{}} {
+		if _, err := client.ExecuteWithContext(context.Background(), &icbc.ICBCRequest{ServiceUrl: "https://gw.example.com" + icbc.PathOrderQuery}, "", res); err != nil {
+			t.Fatalf("ExecuteWithContext(%T): %v", res, err)
+		}
+		b, err := json.Marshal(res)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if !strings.Contains(string(b), `"total_amt":"100"`) || !strings.Contains(string(b), `"sign":"`) {
+			t.Errorf("%T decoded as %s", res, b)
+		}
+	}
+}
+
+// BenchmarkExecuteLargeOrderQuery 执行返回约 1MB 订单查询响应的调用，统计读取、验签和解析的分配
+func BenchmarkExecuteLargeOrderQuery(b *testing.B) {
+	var body []byte
+	client, gatewayKey := newStaticClient(b, func() []byte { return body })
+	promotion := make([]string, 5000)
+	for i := range promotion {
+		promotion[i] = `{\"promotion_id\":\"P00000000000000000` + strings.Repeat("0", 4) + `\",\"name\":\"满100减10优惠活动\",\"amount\":\"10\",\"type\":\"COUPON\",\"scope\":\"GLOBAL\",\"merchant_contribute\":\"5\",\"other_contribute\":\"5\",\"goods_detail\":[]}`
+	}
+	biz := `{"return_code":"0","return_msg":"success","msg_id":"m","pay_status":"1","total_amt":"100","out_trade_no":"T1",` +
+		`"promotion_detail":"[` + strings.Join(promotion, ",") + `]"}`
+	body = signedResponse(b, gatewayKey, biz, "")
+	request := &icbc.ICBCRequest{
+		ServiceUrl: "https://gw.example.com" + icbc.PathOrderQuery,
+		BizContent: &icbc.OrderQueryRequest{OutTradeNo: "T1"},
+	}
+
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resp := &icbc.OrderQueryResp{}
+		if _, err := client.ExecuteWithContext(context.Background(), request, "", resp); err != nil {
+			b.Fatalf("ExecuteWithContext: %v", err)
+		}
+		if resp.ResponseBizContent.PayStatus != "1" {
+			b.Fatalf("pay_status = %s", resp.ResponseBizContent.PayStatus)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package icbc_api_sdk_go
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// RetryPolicy 网关临时故障的重试策略
+//
+// 重试时复用同一个 msg_id 重新发送完全相同的请求。默认只重试 ICBCRequest.Idempotent 为 true 的请求，
+// 退款等非幂等接口需要显式设置 RetryNonIdempotent 才会重试；验签失败永远不会重试。
+type RetryPolicy struct {
+	MaxAttempts int     // 最大尝试次数(包含首次请求)，小于等于 1 时不重试
+	Backoff     Backoff // 重试间隔退避配置，零值时使用 DefaultBackoff
+	// Retryable 判断错误是否可重试，为 nil 时使用 DefaultRetryable
+	// 无论返回什么，验签失败都不会重试
+	Retryable func(err error) bool
+	// RetryNonIdempotent 是否允许重试非幂等请求(如退款)
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryable 默认的可重试判断：超时(包括 http.Client 的单次请求超时)、连接被拒绝或重置、
+// 连接意外关闭以及 HTTP 408/429/5xx 可重试；context 取消、证书错误等其他网络错误、验签失败均不重试。
+// 调用方 context 结束后无论判断结果如何都不会再重试。
+//
+// 参数:
+//   - err: 单次请求返回的错误
+//
+// 返回值:
+//   - bool: 是否可重试
+func DefaultRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.StatusCode == http.StatusRequestTimeout,
+			statusErr.StatusCode == http.StatusTooManyRequests,
+			statusErr.StatusCode >= http.StatusInternalServerError:
+			return true
+		default:
+			return false
+		}
+	}
+	// *url.Error 也实现了 net.Error，只按超时判断，避免重试证书错误、不支持的协议等永久性错误
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// attempts 返回请求允许的最大尝试次数
+//
+// 参数:
+//   - request: 请求对象
+//
+// 返回值:
+//   - int: 最大尝试次数，不重试时为 1
+func (p *RetryPolicy) attempts(request *ICBCRequest) int {
+	if p == nil || p.MaxAttempts <= 1 {
+		return 1
+	}
+	if !request.Idempotent && !p.RetryNonIdempotent {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// retryable 判断错误是否可重试
+//
+// 参数:
+//   - err: 单次请求返回的错误
+//
+// 返回值:
+//   - bool: 是否可重试
+func (p *RetryPolicy) retryable(err error) bool {
+	if p == nil || errors.Is(err, ErrSignatureVerification) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return DefaultRetryable(err)
+}
+
+// backoff 返回重试间隔退避配置
+//
+// 返回值:
+//   - Backoff: 退避配置
+func (p *RetryPolicy) backoff() Backoff {
+	if p == nil || p.Backoff == (Backoff{}) {
+		return DefaultBackoff
+	}
+	return p.Backoff
+}
@@ -0,0 +1,137 @@
+package icbc_api_sdk_go_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+)
+
+func TestDefaultRetryable(t *testing.T) {
+	urlErr := func(err error) error {
+		return &url.Error{Op: "Post", URL: "https://gw.example.com", Err: err}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", urlErr(context.Canceled), false},
+		{"client timeout", urlErr(os.ErrDeadlineExceeded), true},
+		{"connection refused", urlErr(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), true},
+		{"connection reset", urlErr(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), true},
+		{"unexpected eof", urlErr(io.ErrUnexpectedEOF), true},
+		{"eof", urlErr(io.EOF), true},
+		{"unknown certificate", urlErr(x509.UnknownAuthorityError{}), false},
+		{"unsupported scheme", urlErr(errors.New("unsupported protocol scheme \"ftp\"")), false},
+		{"status 502", &icbc.StatusError{StatusCode: http.StatusBadGateway}, true},
+		{"status 408", &icbc.StatusError{StatusCode: http.StatusRequestTimeout}, true},
+		{"status 429", &icbc.StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"status 400", &icbc.StatusError{StatusCode: http.StatusBadRequest}, false},
+		{"signature", fmt.Errorf("%w: bad sign", icbc.ErrSignatureVerification), false},
+	}
+	for _, tt := range tests {
+		if got := icbc.DefaultRetryable(tt.err); got != tt.want {
+			t.Errorf("DefaultRetryable(%s: %v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		policy       icbc.RetryPolicy
+		fault        icbctest.Fault
+		wantErr      bool
+		wantAttempts int
+	}{
+		{
+			name:         "idempotent query retried",
+			path:         icbc.PathOrderQuery,
+			policy:       icbc.RetryPolicy{MaxAttempts: 3, Backoff: fastBackoff},
+			fault:        icbctest.Fault{Times: 2, HTTPStatus: http.StatusServiceUnavailable},
+			wantAttempts: 3,
+		},
+		{
+			name:         "attempts exhausted",
+			path:         icbc.PathOrderQuery,
+			policy:       icbc.RetryPolicy{MaxAttempts: 2, Backoff: fastBackoff},
+			fault:        icbctest.Fault{HTTPStatus: http.StatusServiceUnavailable},
+			wantErr:      true,
+			wantAttempts: 2,
+		},
+		{
+			name:         "client error not retried",
+			path:         icbc.PathOrderQuery,
+			policy:       icbc.RetryPolicy{MaxAttempts: 3, Backoff: fastBackoff},
+			fault:        icbctest.Fault{HTTPStatus: http.StatusBadRequest},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "bad signature not retried",
+			path:         icbc.PathOrderQuery,
+			policy:       icbc.RetryPolicy{MaxAttempts: 3, Backoff: fastBackoff, Retryable: func(error) bool { return true }},
+			fault:        icbctest.Fault{BadSignature: true, ReturnCode: icbc.ReturnCodeSuccess},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "refund not retried",
+			path:         icbc.PathRefund,
+			policy:       icbc.RetryPolicy{MaxAttempts: 3, Backoff: fastBackoff},
+			fault:        icbctest.Fault{Times: 1, HTTPStatus: http.StatusServiceUnavailable},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "refund retried when allowed",
+			path:         icbc.PathRefund,
+			policy:       icbc.RetryPolicy{MaxAttempts: 3, Backoff: fastBackoff, RetryNonIdempotent: true},
+			fault:        icbctest.Fault{Times: 1, HTTPStatus: http.StatusServiceUnavailable},
+			wantAttempts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway, client := newGatewayClient(t)
+			gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100, PayStatus: icbc.PayStatusSuccess})
+			client.RetryPolicy = &tt.policy
+			fault := tt.fault
+			fault.Path = tt.path
+			gateway.InjectFault(fault)
+
+			var call *icbc.CallResult
+			var err error
+			if tt.path == icbc.PathRefund {
+				_, call, err = client.Refund(context.Background(), &icbc.RefundRequest{OutTradeNo: "T1", OuttrxSerialNo: "R1", RetTotalAmt: "1"})
+			} else {
+				_, call, err = client.QueryOrder(context.Background(), &icbc.OrderQueryRequest{OutTradeNo: "T1"})
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if call.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", call.Attempts, tt.wantAttempts)
+			}
+			// 重试复用同一个 msg_id
+			for _, req := range gateway.Requests() {
+				if req.MsgID != call.MsgID {
+					t.Errorf("retry used msg_id %s, want %s", req.MsgID, call.MsgID)
+				}
+			}
+		})
+	}
+}
@@ -20,6 +20,33 @@ const (
 	PublicKeySuffix  = "-----END PUBLIC KEY-----"
 )
 
+// 工行支持的签名类型
+const (
+	SignTypeRSA  = "RSA"  // SHA1WithRSA
+	SignTypeRSA2 = "RSA2" // SHA256WithRSA
+)
+
+// SignWithType 按工行签名类型签名，RSA 使用 SHA-1，RSA2 使用 SHA-256
+//
+// 参数:
+//   - signType: 签名类型，为空时按 RSA2 处理
+//   - data: 待签名的数据
+//   - privateKey:  Base64 编码的 RSA 私钥字符串
+//
+// 返回值:
+//   - string: 签名后的 Base64 编码字符串
+//   - error: 签名类型不受支持或签名失败时返回错误
+func SignWithType(signType, data, privateKey string) (string, error) {
+	switch signType {
+	case SignTypeRSA:
+		return SignWithSHA1RSA(data, privateKey)
+	case SignTypeRSA2, "":
+		return SignWithSHA256RSA(data, privateKey)
+	default:
+		return "", fmt.Errorf("unsupported sign type %q, expected %s or %s", signType, SignTypeRSA2, SignTypeRSA)
+	}
+}
+
 // SignWithSHA256RSA 使用 SHA-256 哈希算法和 RSA 私钥对数据进行签名
 //
 // 参数:
@@ -30,6 +57,53 @@ const (
 //   - string: 签名后的 Base64 编码字符串
 //   - error: 签名过程中出现的错误
 func SignWithSHA256RSA(data, privateKey string) (string, error) {
+	pk, err := parseRSAPrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	// 对数据进行 SHA-256 哈希
+	digest := sha256.Sum256([]byte(data))
+	// 使用 RSA-PKCS1v15 签名算法对哈希值进行签名
+	signature, signErr := rsa.SignPKCS1v15(rand.Reader, pk, crypto.SHA256, digest[:])
+	// 检查签名是否成功
+	if signErr != nil {
+		return "", fmt.Errorf("could not sign message:%w", signErr)
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// SignWithSHA1RSA 使用 SHA-1 哈希算法和 RSA 私钥对数据进行签名，对应工行 sign_type 为 RSA 的签名方式
+//
+// 参数:
+//   - data: 待签名的数据
+//   - privateKey:  Base64 编码的 RSA 私钥字符串
+//
+// 返回值:
+//   - string: 签名后的 Base64 编码字符串
+//   - error: 签名过程中出现的错误
+func SignWithSHA1RSA(data, privateKey string) (string, error) {
+	pk, err := parseRSAPrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	// 对数据进行 SHA1 哈希
+	digest := sha1.Sum([]byte(data))
+	signature, signErr := rsa.SignPKCS1v15(rand.Reader, pk, crypto.SHA1, digest[:])
+	if signErr != nil {
+		return "", fmt.Errorf("could not sign message:%w", signErr)
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey 解析 Base64 编码的 PKCS8 RSA 私钥，自动补全 PEM 开始结束标记
+//
+// 参数:
+//   - privateKey: Base64 编码的 RSA 私钥字符串
+//
+// 返回值:
+//   - *rsa.PrivateKey: RSA 私钥
+//   - error: 错误信息
+func parseRSAPrivateKey(privateKey string) (*rsa.PrivateKey, error) {
 	privateKey = strings.TrimSpace(privateKey)
 	//判断私钥是否包含开始结束标记
 	if !strings.HasPrefix(privateKey, PrivateKeyPrefix) {
@@ -42,27 +116,19 @@ func SignWithSHA256RSA(data, privateKey string) (string, error) {
 	//解析PEM私钥
 	pemBlock, _ := pem.Decode([]byte(privateKey))
 	if pemBlock == nil {
-		return "", fmt.Errorf("failed to decode PEM block containing the private key")
+		return nil, fmt.Errorf("failed to decode PEM block containing the private key")
 	}
 	//解析 PEM 格式的私钥块
 	privateKeyInterface, err := x509.ParsePKCS8PrivateKey(pemBlock.Bytes)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse PKCS8 private key: %w", err)
+		return nil, fmt.Errorf("failed to parse PKCS8 private key: %w", err)
 	}
 	// 类型断言，确保是 RSA 私钥
 	pk, ok := privateKeyInterface.(*rsa.PrivateKey)
 	if !ok {
-		return "", fmt.Errorf("private key is not RSA")
-	}
-	// 对数据进行 SHA-256 哈希
-	digest := sha256.Sum256([]byte(data))
-	// 使用 RSA-PKCS1v15 签名算法对哈希值进行签名
-	signature, signErr := rsa.SignPKCS1v15(rand.Reader, pk, crypto.SHA256, digest[:])
-	// 检查签名是否成功
-	if signErr != nil {
-		return "", fmt.Errorf("could not sign message:%w", signErr)
+		return nil, fmt.Errorf("private key is not RSA")
 	}
-	return base64.StdEncoding.EncodeToString(signature), nil
+	return pk, nil
 }
 
 // VerifySHA1RSA 使用 SHA-1 哈希算法和 RSA 公钥验证数据的签名
@@ -91,6 +157,60 @@ func SignWithSHA256RSA(data, privateKey string) (string, error) {
 // 返回值:
 //   - bool: 如果签名验证成功则返回 true，否则返回 false
 func VerifySHA1RSA(data, signature, publicKey string) (bool, error) {
+	pk, err := parseRSAPublicKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	// 对数据进行 SHA1 哈希
+	digest := sha1.Sum([]byte(data))
+	err = rsa.VerifyPKCS1v15(pk, crypto.SHA1, digest[:], signatureBytes)
+	if err != nil {
+		return false, fmt.Errorf("could not verify signature:%w", err)
+	}
+	return true, nil
+}
+
+// VerifySHA256RSA 使用 SHA-256 哈希算法和 RSA 公钥验证数据的签名，用于校验 SignWithSHA256RSA 生成的签名
+//
+// 参数:
+//   - data: 待验证的数据
+//   - signature: Base64 编码的签名字符串
+//   - publicKey: Base64 编码的 RSA 公钥字符串
+//
+// 返回值:
+//   - bool: 如果签名验证成功则返回 true，否则返回 false
+//   - error: 验证过程中出现的错误
+func VerifySHA256RSA(data, signature, publicKey string) (bool, error) {
+	pk, err := parseRSAPublicKey(publicKey)
+	if err != nil {
+		return false, err
+	}
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	// 对数据进行 SHA-256 哈希
+	digest := sha256.Sum256([]byte(data))
+	err = rsa.VerifyPKCS1v15(pk, crypto.SHA256, digest[:], signatureBytes)
+	if err != nil {
+		return false, fmt.Errorf("could not verify signature:%w", err)
+	}
+	return true, nil
+}
+
+// parseRSAPublicKey 解析 Base64 编码的 PKIX RSA 公钥，自动补全 PEM 开始结束标记
+//
+// 参数:
+//   - publicKey: Base64 编码的 RSA 公钥字符串
+//
+// 返回值:
+//   - *rsa.PublicKey: RSA 公钥
+//   - error: 错误信息
+func parseRSAPublicKey(publicKey string) (*rsa.PublicKey, error) {
 	publicKey = strings.TrimSpace(publicKey)
 	if !strings.HasPrefix(publicKey, PublicKeyPrefix) {
 		publicKey = PublicKeyPrefix + "\n" + publicKey
@@ -102,26 +222,16 @@ func VerifySHA1RSA(data, signature, publicKey string) (bool, error) {
 	//解析PEM公钥
 	pemBlock, _ := pem.Decode([]byte(publicKey))
 	if pemBlock == nil {
-		return false, fmt.Errorf("failed to decode PEM block containing the private key")
+		return nil, fmt.Errorf("failed to decode PEM block containing the public key")
 	}
-	//解析 PEM 格式的私钥块
+	//解析 PEM 格式的公钥块
 	pkcs1PublicKey, err := x509.ParsePKIXPublicKey(pemBlock.Bytes)
 	if err != nil {
-		return false, fmt.Errorf("failed to parse PKCS1 public key: %w", err)
+		return nil, fmt.Errorf("failed to parse PKCS1 public key: %w", err)
 	}
 	pk, ok := pkcs1PublicKey.(*rsa.PublicKey)
 	if !ok {
-		return false, fmt.Errorf("public key is not RSA")
+		return nil, fmt.Errorf("public key is not RSA")
 	}
-	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
-	if err != nil {
-		return false, fmt.Errorf("failed to decode signature: %w", err)
-	}
-	// 对数据进行 SHA1 哈希
-	digest := sha1.Sum([]byte(data))
-	err = rsa.VerifyPKCS1v15(pk, crypto.SHA1, digest[:], signatureBytes)
-	if err != nil {
-		return false, fmt.Errorf("could not verify signature:%w", err)
-	}
-	return true, nil
+	return pk, nil
 }
@@ -0,0 +1,93 @@
+package icbc_api_sdk_go_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+)
+
+func TestSignWithType(t *testing.T) {
+	privateKey, publicKey, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	tests := []struct {
+		signType string
+		sha1     bool // true 时签名应能用 SHA1 验证，否则应能用 SHA256 验证
+	}{
+		{icbc.SignTypeRSA, true},
+		{icbc.SignTypeRSA2, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		sign, err := icbc.SignWithType(tt.signType, "a=1&b=2", privateKey)
+		if err != nil {
+			t.Fatalf("SignWithType(%q): %v", tt.signType, err)
+		}
+		sha1Pass, _ := icbc.VerifySHA1RSA("a=1&b=2", sign, publicKey)
+		sha256Pass, _ := icbc.VerifySHA256RSA("a=1&b=2", sign, publicKey)
+		if sha1Pass != tt.sha1 || sha256Pass == tt.sha1 {
+			t.Errorf("SignWithType(%q): SHA1 verify = %v, SHA256 verify = %v, want SHA1 = %v", tt.signType, sha1Pass, sha256Pass, tt.sha1)
+		}
+	}
+	if _, err := icbc.SignWithType("MD5", "a=1", privateKey); err == nil {
+		t.Error("SignWithType(MD5) succeeded, want error")
+	}
+}
+
+func TestExecuteSignTypes(t *testing.T) {
+	merchantKey, merchantPub, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	gateway, err := icbctest.NewGateway(merchantPub)
+	if err != nil {
+		t.Fatalf("NewGateway: %v", err)
+	}
+	defer gateway.Close()
+	gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100})
+
+	for _, signType := range []string{icbc.SignTypeRSA, icbc.SignTypeRSA2} {
+		t.Run(signType, func(t *testing.T) {
+			client := gateway.Client("10000000000000000001", merchantKey)
+			client.SignType = signType
+			// 网关按请求中的 sign_type 选择 SHA1 或 SHA256 验签，签名算法不一致时返回错误
+			var resp icbc.OrderQueryResp
+			request := &icbc.ICBCRequest{
+				ServiceUrl: gateway.ServiceURL(icbc.PathOrderQuery),
+				BizContent: &icbc.OrderQueryRequest{OutTradeNo: "T1"},
+			}
+			if _, err := client.ExecuteWithContext(context.Background(), request, "", &resp); err != nil {
+				t.Fatalf("ExecuteWithContext: %v", err)
+			}
+			if resp.ResponseBizContent.ReturnCode != icbc.ReturnCodeSuccess {
+				t.Errorf("return_code = %s, want %s", resp.ResponseBizContent.ReturnCode, icbc.ReturnCodeSuccess)
+			}
+
+			// 异步通知应答使用同一签名类型
+			notifyResp, err := client.BuildNotifyResponse(0, "success", "msg1")
+			if err != nil {
+				t.Fatalf("BuildNotifyResponse: %v", err)
+			}
+			content, signJson, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(notifyResp, "{"), "}"), `,"sign":`)
+			if !ok {
+				t.Fatalf("notify response has no sign: %s", notifyResp)
+			}
+			var sign string
+			if err := json.Unmarshal([]byte(signJson), &sign); err != nil {
+				t.Fatalf("invalid sign %s: %v", signJson, err)
+			}
+			verify := icbc.VerifySHA256RSA
+			if signType == icbc.SignTypeRSA {
+				verify = icbc.VerifySHA1RSA
+			}
+			if pass, err := verify(content, sign, merchantPub); !pass {
+				t.Errorf("notify response sign does not verify with %s: %v", signType, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package icbc_api_sdk_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PollOptions 支付结果轮询配置
+type PollOptions struct {
+	Backoff     Backoff // 轮询间隔退避配置，零值时使用 DefaultBackoff
+	MaxAttempts int     // 最大查询次数，0 表示不限制，直到终态、永久性错误或 context 结束
+	// Retryable 判断查询失败后是否继续轮询，为 nil 时使用 DefaultRetryable
+	// 无论返回什么，验签失败都会立即终止轮询
+	Retryable func(err error) bool
+	// RetryableReturnCode 判断非成功的 return_code 是否继续轮询，为 nil 时任何非成功返回码都立即终止轮询
+	RetryableReturnCode func(returnCode string) bool
+	// OnStatus 每次查询后的回调，用于上报中间状态和记录 msg_id、状态码、耗时等调用信息
+	// attempt 从 1 开始；查询失败时 resp 为 nil，err 为失败原因；签名前失败时 result 为 nil
+	OnStatus func(attempt int, resp *OrderQueryResp, result *CallResult, err error)
+}
+
+// WaitForPayment 轮询订单查询接口，直到 pay_status 为终态或 context 结束
+//
+// 接口地址由 BaseURL 或 Environment 与 PathOrderQuery 拼接。网络超时、HTTP 5xx 等临时错误会通过 OnStatus
+// 回调上报后按退避间隔继续查询；验签失败、私钥错误等永久性错误和非成功的 return_code(如订单不存在)
+// 会立即终止轮询，可通过 Retryable 和 RetryableReturnCode 调整。
+//
+// 参数:
+//   - ctx: 上下文，用于控制整体等待时长
+//   - query: 订单查询请求
+//   - opts: 轮询配置，可为 nil
+//
+// 返回值:
+//   - *OrderQueryResp: 终态时的查询结果；未到终态时为最后一次成功的查询结果
+//   - error: 错误信息
+func (c *DefaultClient) WaitForPayment(ctx context.Context, query *OrderQueryRequest, opts *PollOptions) (*OrderQueryResp, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context cannot be nil")
+	}
+	if query == nil {
+		return nil, fmt.Errorf("query cannot be nil")
+	}
+	serviceUrl, err := c.ServiceURL(PathOrderQuery)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &PollOptions{}
+	}
+	backoff := opts.Backoff
+	if backoff == (Backoff{}) {
+		backoff = DefaultBackoff
+	}
+	retryable := opts.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+
+	request := &ICBCRequest{
+		ServiceUrl: serviceUrl,
+		BizContent: query,
+		Method:     "POST",
+		Idempotent: true,
+	}
+
+	var last *OrderQueryResp
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		resp := &OrderQueryResp{}
+		result, err := c.ExecuteCall(ctx, request, "", resp)
+		if opts.OnStatus != nil {
+			if err != nil {
+				opts.OnStatus(attempt, nil, result, err)
+			} else {
+				opts.OnStatus(attempt, resp, result, nil)
+			}
+		}
+		if err != nil {
+			if errors.Is(err, ErrSignatureVerification) || !retryable(err) {
+				return last, fmt.Errorf("failed to query order: %w", err)
+			}
+			lastErr = err
+		} else {
+			last = resp
+			lastErr = nil
+			biz := resp.ResponseBizContent
+			if biz.ReturnCode != ReturnCodeSuccess {
+				returnCodeErr := fmt.Errorf("query order return code %s: %s", biz.ReturnCode, biz.ReturnMsg)
+				if opts.RetryableReturnCode == nil || !opts.RetryableReturnCode(biz.ReturnCode) {
+					return resp, returnCodeErr
+				}
+				lastErr = returnCodeErr
+			} else if IsFinalPayStatus(biz.PayStatus) {
+				return resp, nil
+			}
+		}
+
+		if opts.MaxAttempts > 0 && attempt >= opts.MaxAttempts {
+			return last, waitPaymentError(fmt.Errorf("max attempts %d reached", opts.MaxAttempts), lastErr)
+		}
+		if err := sleepContext(ctx, backoff.Delay(attempt-1)); err != nil {
+			return last, waitPaymentError(err, lastErr)
+		}
+	}
+}
+
+// waitPaymentError 组合轮询终止原因与最后一次查询错误
+func waitPaymentError(reason, lastErr error) error {
+	if lastErr != nil {
+		return fmt.Errorf("payment not final: %w, last error: %w", reason, lastErr)
+	}
+	return fmt.Errorf("payment not final: %w", reason)
+}
@@ -0,0 +1,121 @@
+package icbc_api_sdk_go_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	icbc "github.com/ljjdev/icbc-api-sdk-go"
+	"github.com/ljjdev/icbc-api-sdk-go/icbctest"
+)
+
+// fastBackoff 测试使用的 1 毫秒固定间隔
+var fastBackoff = icbc.Backoff{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Multiplier: 1}
+
+// newGatewayClient 启动模拟网关并返回连接到它的客户端
+func newGatewayClient(t *testing.T) (*icbctest.Gateway, *icbc.DefaultClient) {
+	t.Helper()
+	merchantKey, merchantPub, err := icbctest.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+	gateway, err := icbctest.NewGateway(merchantPub)
+	if err != nil {
+		t.Fatalf("NewGateway: %v", err)
+	}
+	t.Cleanup(gateway.Close)
+	return gateway, gateway.Client("10000000000000000001", merchantKey)
+}
+
+func TestWaitForPayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(g *icbctest.Gateway, c *icbc.DefaultClient, opts *icbc.PollOptions)
+		maxAttempt int
+		wantErr    bool
+		wantStatus string
+		wantCalls  int
+	}{
+		{
+			name: "paid after polling",
+			setup: func(g *icbctest.Gateway, c *icbc.DefaultClient, opts *icbc.PollOptions) {
+				opts.OnStatus = func(attempt int, resp *icbc.OrderQueryResp, result *icbc.CallResult, err error) {
+					if attempt == 2 {
+						_ = g.Pay(context.Background(), "T1")
+					}
+				}
+			},
+			wantStatus: icbc.PayStatusSuccess,
+			wantCalls:  3,
+		},
+		{
+			name: "transient gateway error",
+			setup: func(g *icbctest.Gateway, c *icbc.DefaultClient, opts *icbc.PollOptions) {
+				g.InjectFault(icbctest.Fault{Path: icbc.PathOrderQuery, Times: 2, HTTPStatus: http.StatusBadGateway})
+				_ = g.Pay(context.Background(), "T1")
+			},
+			wantStatus: icbc.PayStatusSuccess,
+			wantCalls:  3,
+		},
+		{
+			name: "order not found",
+			setup: func(g *icbctest.Gateway, c *icbc.DefaultClient, opts *icbc.PollOptions) {
+				g.InjectFault(icbctest.Fault{Path: icbc.PathOrderQuery, ReturnCode: icbctest.ReturnCodeOrderNotFound, ReturnMsg: "order not found"})
+			},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name: "retryable return code",
+			setup: func(g *icbctest.Gateway, c *icbc.DefaultClient, opts *icbc.PollOptions) {
+				g.InjectFault(icbctest.Fault{Path: icbc.PathOrderQuery, ReturnCode: "500001", ReturnMsg: "system busy"})
+				opts.RetryableReturnCode = func(returnCode string) bool { return returnCode == "500001" }
+			},
+			maxAttempt: 3,
+			wantErr:    true,
+			wantCalls:  3,
+		},
+		{
+			name: "bad signature",
+			setup: func(g *icbctest.Gateway, c *icbc.DefaultClient, opts *icbc.PollOptions) {
+				g.InjectFault(icbctest.Fault{Path: icbc.PathOrderQuery, BadSignature: true, ReturnCode: icbc.ReturnCodeSuccess})
+			},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name: "invalid private key",
+			setup: func(g *icbctest.Gateway, c *icbc.DefaultClient, opts *icbc.PollOptions) {
+				c.PrivateKey = "invalid"
+			},
+			wantErr:   true,
+			wantCalls: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway, client := newGatewayClient(t)
+			gateway.AddOrder(icbctest.Order{OutTradeNo: "T1", TotalAmt: 100})
+			opts := &icbc.PollOptions{Backoff: fastBackoff, MaxAttempts: tt.maxAttempt}
+			tt.setup(gateway, client, opts)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			resp, err := client.WaitForPayment(ctx, &icbc.OrderQueryRequest{OutTradeNo: "T1"}, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitForPayment error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("WaitForPayment polled until the context ended: %v", err)
+			}
+			if tt.wantStatus != "" && (resp == nil || resp.ResponseBizContent.PayStatus != tt.wantStatus) {
+				t.Errorf("WaitForPayment resp = %+v, want pay_status %s", resp, tt.wantStatus)
+			}
+			if n := len(gateway.Requests()); n != tt.wantCalls {
+				t.Errorf("gateway received %d queries, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
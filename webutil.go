@@ -2,11 +2,11 @@ package icbc_api_sdk_go
 
 import (
 	"fmt"
+	"html"
+	"log/slog"
 	URL "net/url"
 	"strings"
 	"time"
-
-	"github.com/igrmk/treemap/v2"
 )
 
 var ApiParamNames = []string{
@@ -34,27 +34,28 @@ func GetCurrentTime() string {
 	return FormatTime(time.Now())
 }
 
-// BuildForm 构建POST表单页面
+// BuildForm 构建POST表单页面，参数值经过HTML转义
+//
+// 渲染失败时通过 slog.Default 记录错误并返回空字符串。
+//
+// Deprecated: 使用 BuildFormWithOptions，渲染失败时可以得到错误信息。
 //
 // 参数:
 //   - baseUrl: 表单提交的目标URL
 //   - params: 表单参数
 //
 // 返回值:
-//   - string: 构建好的HTML表单
+//   - string: 构建好的HTML表单，渲染失败时为空字符串
 func BuildForm(baseUrl string, params *IcbcMap) string {
-	var sb strings.Builder
-	sb.WriteString("<form name=\"auto_submit_form\" method=\"post\" action=\"")
-	sb.WriteString(baseUrl)
-	sb.WriteString("\">")
-	sb.WriteString(BuildHiddenFields(params))
-	sb.WriteString("<input type=\"submit\" value=\"立刻提交\" style=\"display:none\" >\n")
-	sb.WriteString("</form>\n")
-	sb.WriteString("<script>document.forms[0].submit();</script>")
-	return sb.String()
+	form, err := BuildFormWithOptions(baseUrl, params, nil)
+	if err != nil {
+		slog.Default().Error("icbc build form failed", slog.String("action", baseUrl), slog.String("error", err.Error()))
+		return ""
+	}
+	return form
 }
 
-// BuildHiddenFields 构建隐藏域字段
+// BuildHiddenFields 构建隐藏域字段，按参数名的字典序输出
 //
 // 参数:
 //   - params: 表单参数
@@ -67,7 +68,7 @@ func BuildHiddenFields(params *IcbcMap) string {
 	}
 
 	var sb strings.Builder
-	for key, value := range params.data {
+	for key, value := range params.All() {
 		if value != "" {
 			sb.WriteString(BuildHiddenFieldsWithKV(key, value))
 		}
@@ -86,9 +87,9 @@ func BuildHiddenFields(params *IcbcMap) string {
 func BuildHiddenFieldsWithKV(key, value string) string {
 	var sb strings.Builder
 	sb.WriteString("<input type=\"hidden\" name=\"")
-	sb.WriteString(key)
+	sb.WriteString(html.EscapeString(key))
 	sb.WriteString("\" value=\"")
-	sb.WriteString(strings.ReplaceAll(value, "\"", "&quot;"))
+	sb.WriteString(html.EscapeString(value))
 	sb.WriteString("\" >\n")
 	return sb.String()
 }
@@ -115,7 +116,7 @@ func BuildGetUrl(strUrl string, icbcMap *IcbcMap) (string, error) {
 		return "", fmt.Errorf("failed to parse url: %w", err)
 	}
 	q := u.Query()
-	for k, v := range icbcMap.data {
+	for k, v := range icbcMap.All() {
 		if q.Has(k) {
 			q.Set(k, v)
 		} else {
@@ -139,16 +140,11 @@ func BuildOrderedSignStr(params *IcbcMap, path string) string {
 		return path
 	}
 
-	tr := treemap.New[string, string]()
-	for k, v := range params.data {
-		tr.Set(k, v)
-	}
-
 	// 拼接有序参数
 	var queryParts []string
-	for it := tr.Iterator(); it.Valid(); it.Next() {
-		if it.Key() != "" && it.Value() != "" {
-			queryParts = append(queryParts, it.Key()+"="+it.Value())
+	for k, v := range params.All() {
+		if k != "" && v != "" {
+			queryParts = append(queryParts, k+"="+v)
 		}
 	}
 